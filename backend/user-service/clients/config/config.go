@@ -0,0 +1,61 @@
+package config
+
+import (
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+type ClientConfig struct {
+	client       *http.Client
+	baseURL      string
+	signatureKey string
+}
+
+type IClientConfig interface {
+	Client() *http.Client
+	BaseURL() string
+	SignatureKey() string
+}
+
+type Option func(*ClientConfig)
+
+func NewClientConfig(options ...Option) IClientConfig {
+	clientConfig := &ClientConfig{
+		client: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+		},
+	}
+
+	for _, option := range options {
+		option(clientConfig)
+	}
+
+	return clientConfig
+}
+
+func (c *ClientConfig) Client() *http.Client {
+	return c.client
+}
+
+func (c *ClientConfig) BaseURL() string {
+	return c.baseURL
+}
+
+func (c *ClientConfig) SignatureKey() string {
+	return c.signatureKey
+}
+
+func WithBaseURL(baseURL string) Option {
+	return func(c *ClientConfig) {
+		c.baseURL = baseURL
+	}
+}
+
+func WithSignatureKey(signatureKey string) Option {
+	return func(c *ClientConfig) {
+		c.signatureKey = signatureKey
+	}
+}
@@ -0,0 +1,78 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+	clientConfig "user-service/clients/config"
+	"user-service/common/logger"
+	"user-service/config"
+	"user-service/constants"
+	"user-service/domain/dto"
+)
+
+type NotificationClient struct {
+	client clientConfig.IClientConfig
+}
+
+type INotificationClient interface {
+	SendEmail(context.Context, *dto.EmailRequest) error
+}
+
+func NewNotificationClient(client clientConfig.IClientConfig) INotificationClient {
+	return &NotificationClient{
+		client: client,
+	}
+}
+
+func (nc *NotificationClient) SendEmail(ctx context.Context, req *dto.EmailRequest) error {
+	if nc.client.BaseURL() == "" {
+		logger.FromContext(ctx).Warnf("notification service is not configured, email %q to %s is not sent", req.Subject, req.To)
+		return nil
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	httpReq, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		fmt.Sprintf("%s/api/v1/notifications/email", nc.client.BaseURL()),
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return err
+	}
+
+	requestAt := strconv.FormatInt(time.Now().Unix(), 10)
+	apiKey := sha256.Sum256([]byte(fmt.Sprintf("%s:%s:%s", config.Get().AppName, nc.client.SignatureKey(), requestAt)))
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set(constants.XServiceName, config.Get().AppName)
+	httpReq.Header.Set(constants.XApiKey, hex.EncodeToString(apiKey[:]))
+	httpReq.Header.Set(constants.XRequestAt, requestAt)
+	if requestID := logger.RequestID(ctx); requestID != "" {
+		httpReq.Header.Set(constants.XRequestID, requestID)
+	}
+
+	resp, err := nc.client.Client().Do(httpReq)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("failed to send email: %v", err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		logger.FromContext(ctx).Errorf("notification service responded with status %d", resp.StatusCode)
+		return fmt.Errorf("notification service responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package clients
+
+import (
+	clientConfig "user-service/clients/config"
+	"user-service/clients/notification"
+	"user-service/config"
+)
+
+type ClientRegistry struct{}
+
+type IClientRegistry interface {
+	GetNotification() notification.INotificationClient
+}
+
+func NewClientRegistry() IClientRegistry {
+	return &ClientRegistry{}
+}
+
+func (c *ClientRegistry) GetNotification() notification.INotificationClient {
+	return notification.NewNotificationClient(
+		clientConfig.NewClientConfig(
+			clientConfig.WithBaseURL(config.Get().InternalService.Notification.Host),
+			clientConfig.WithSignatureKey(config.Get().InternalService.Notification.SignatureKey),
+		),
+	)
+}
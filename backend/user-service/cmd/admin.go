@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"user-service/clients"
+	"user-service/common/dbresolver"
+	"user-service/common/storage"
+	"user-service/common/util"
+	"user-service/config"
+	"user-service/domain/dto"
+	"user-service/repositories"
+	"user-service/repositories/scope"
+	"user-service/services"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var admin dto.RegisterRequest
+
+var createAdminCommand = &cobra.Command{
+	Use:   "create-admin",
+	Short: "Create an administrator account",
+	Long: "Create an administrator account. The password is read from --password or " +
+		"ADMIN_PASSWORD, otherwise a random one is generated and printed once.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		loadConfig()
+		db := openDatabase(cmd.Context())
+
+		err := scope.RegisterOrganizationScope(db)
+		if err != nil {
+			return err
+		}
+
+		fileStorage, err := storage.NewStorage(config.Get().Storage)
+		if err != nil {
+			return err
+		}
+
+		generated := false
+		if admin.Password == "" {
+			admin.Password = os.Getenv("ADMIN_PASSWORD")
+		}
+		if admin.Password == "" {
+			admin.Password, err = util.GeneratePassword()
+			if err != nil {
+				return err
+			}
+			generated = true
+		}
+
+		service := services.NewServiceRegistry(
+			repositories.NewRepositoryRegistry(dbresolver.NewResolver(db, nil)),
+			clients.NewClientRegistry(),
+			fileStorage,
+		)
+		user, err := service.GetUser().CreateAdmin(cmd.Context(), &admin)
+		if err != nil {
+			return err
+		}
+
+		logrus.Infof("admin %s created with uuid %s", user.Username, user.UUID)
+		if generated {
+			fmt.Printf("generated password for %s: %s\n", user.Username, admin.Password)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	flags := createAdminCommand.Flags()
+	flags.StringVar(&admin.Name, "name", "Administrator", "display name")
+	flags.StringVar(&admin.Username, "username", "", "login username")
+	flags.StringVar(&admin.Email, "email", "", "email address")
+	flags.StringVar(&admin.PhoneNumber, "phone", "", "phone number")
+	flags.StringVar(&admin.Password, "password", "", "password, prefer ADMIN_PASSWORD to keep it out of shell history")
+	_ = createAdminCommand.MarkFlagRequired("username")
+	_ = createAdminCommand.MarkFlagRequired("email")
+	_ = createAdminCommand.MarkFlagRequired("phone")
+}
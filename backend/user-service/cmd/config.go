@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"user-service/config"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+)
+
+var printRedacted bool
+
+var configCommand = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration",
+}
+
+var configPrintCommand = &cobra.Command{
+	Use:   "print",
+	Short: "Print the effective configuration and validate it",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_ = godotenv.Load()
+		cfg, layers, err := config.Resolve()
+		if err != nil {
+			return err
+		}
+		defer config.DiscardLeases(context.Background())
+
+		invalid := config.Validate(cfg)
+		if printRedacted {
+			redacted := config.Redacted(*cfg)
+			cfg = &redacted
+		}
+
+		fmt.Fprintf(os.Stderr, "layers: %s\n", strings.Join(layers, " -> "))
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(cfg)
+		if err != nil {
+			return err
+		}
+
+		return invalid
+	},
+}
+
+func init() {
+	configPrintCommand.Flags().BoolVar(&printRedacted, "redacted", true, "mask secrets, pass --redacted=false to show them")
+	configCommand.AddCommand(configPrintCommand)
+}
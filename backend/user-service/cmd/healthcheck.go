@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+	"user-service/config"
+
+	"github.com/spf13/cobra"
+)
+
+var healthcheckURL string
+
+// healthcheckCommand probes a running server, so container health checks do
+// not depend on curl or wget being in the image.
+var healthcheckCommand = &cobra.Command{
+	Use:   "healthcheck",
+	Short: "Exit non-zero unless the server reports ready",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client := &http.Client{Timeout: 5 * time.Second}
+		resp, err := client.Get(healthcheckURL)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("%s answered %s", healthcheckURL, resp.Status)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = fmt.Sprint(config.Defaults().Port)
+	}
+
+	healthcheckCommand.Flags().StringVar(&healthcheckURL, "url", fmt.Sprintf("http://127.0.0.1:%s/readyz", port), "readiness endpoint to probe")
+}
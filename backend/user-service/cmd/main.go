@@ -1,68 +1,158 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"net"
 	"net/http"
-	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 	"time"
+	"user-service/clients"
+	"user-service/common/dbresolver"
+	"user-service/common/health"
+	"user-service/common/jwtkey"
+	"user-service/common/logger"
+	"user-service/common/metrics"
 	"user-service/common/response"
+	"user-service/common/storage"
+	"user-service/common/telemetry"
 	"user-service/config"
 	"user-service/constants"
 	"user-service/controllers"
+	"user-service/database/migrations"
 	"user-service/database/seeders"
-	"user-service/domain/models"
 	"user-service/middlewares"
 	"user-service/repositories"
+	"user-service/repositories/scope"
 	"user-service/routes"
 	"user-service/services"
 
 	"github.com/didip/tollbooth"
 	"github.com/didip/tollbooth/limiter"
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
-var command = cobra.Command{
+var (
+	skipMigrations bool
+	skipSeed       bool
+)
+
+var serveCommand = &cobra.Command{
 	Use:   "serve",
 	Short: "Start the server",
 	Run: func(cmd *cobra.Command, args []string) {
-		_ = godotenv.Load()
-		config.Init()
+		loadConfig()
+		jwtkey.Set(config.Get().JwtSecretKey)
+		config.Subscribe(reloadLogLevel)
+		config.Subscribe(rotateJwtKey)
 
-		db, err := config.InitDatabase()
+		shutdownTelemetry, err := telemetry.Init(
+			context.Background(),
+			config.Get().Telemetry,
+			config.Get().AppName,
+			config.Get().AppEnv,
+		)
 		if err != nil {
 			panic(err)
 		}
 
-		loc, err := time.LoadLocation(os.Getenv("TIMEZONE"))
+		db := openDatabase(cmd.Context())
+
+		if skipMigrations {
+			logrus.Info("skipping migrations, run `migrate up` before rolling out")
+		} else {
+			err = migrations.Up(cmd.Context(), db)
+			if err != nil {
+				panic(err)
+			}
+		}
+
+		sqlDB, err := db.DB()
 		if err != nil {
 			panic(err)
 		}
 
-		time.Local = loc
+		err = metrics.RegisterDB(sqlDB, dbresolver.PrimaryName)
+		if err != nil {
+			panic(err)
+		}
 
-		err = db.AutoMigrate(
-			&models.Role{},
-			&models.User{},
-		)
+		err = scope.RegisterOrganizationScope(db)
+		if err != nil {
+			panic(err)
+		}
+
+		err = dbresolver.RegisterSessionTracking(db)
 		if err != nil {
 			panic(err)
 		}
 
-		seeder := seeders.NewSeederRegistry(db)
-		seeder.Run()
+		replicas := config.InitReplicas(cmd.Context())
+
+		checks := []health.Check{
+			health.Database(sqlDB),
+			health.Migrations(db),
+			health.Config(time.Duration(config.Get().Health.ConfigMaxAge) * time.Second),
+		}
+		closeReplicas := make([]shutdownStep, 0, len(replicas))
+		for _, replica := range replicas {
+			replicaDB, err := replica.DB.DB()
+			if err != nil {
+				panic(err)
+			}
+
+			err = metrics.RegisterDB(replicaDB, replica.Name)
+			if err != nil {
+				panic(err)
+			}
 
-		repository := repositories.NewRepositoryRegistry(db)
-		service := services.NewServiceRegistry(repository)
+			err = scope.RegisterOrganizationScope(replica.DB)
+			if err != nil {
+				panic(err)
+			}
+
+			checks = append(checks, health.Replica(replica.Name, replicaDB))
+			closeReplicas = append(closeReplicas, shutdownStep{
+				name: replica.Name,
+				run:  func(context.Context) error { return replicaDB.Close() },
+			})
+		}
+
+		if !skipSeed {
+			seeder := seeders.NewSeederRegistry(db, config.Get().AppEnv)
+			err = seeder.Run(cmd.Context(), seeders.Options{})
+			if err != nil {
+				panic(err)
+			}
+		}
+
+		repository := repositories.NewRepositoryRegistry(dbresolver.NewResolver(db, replicas))
+		client := clients.NewClientRegistry()
+		fileStorage, err := storage.NewStorage(config.Get().Storage)
+		if err != nil {
+			panic(err)
+		}
+
+		service := services.NewServiceRegistry(repository, client, fileStorage)
 		controller := controllers.NewRegistryController(service)
 
-		router := gin.Default()
+		router := gin.New()
+		router.MaxMultipartMemory = 8 << 20
+		router.Use(otelgin.Middleware(config.Get().AppName))
+		router.Use(middlewares.RequestID())
+		router.Use(middlewares.DatabaseSession())
 		router.Use(middlewares.HandlePanic())
+		router.Use(middlewares.Metrics())
 		router.NoRoute(func(c *gin.Context) {
 			c.JSON(http.StatusNotFound, response.Response{
-				Status:  constants.Error,
-				Message: fmt.Sprintf("Path %s ", http.StatusText(http.StatusNotFound)),
+				Status:    constants.Error,
+				Message:   fmt.Sprintf("Path %s ", http.StatusText(http.StatusNotFound)),
+				RequestID: logger.RequestID(c.Request.Context()),
 			})
 		})
 		router.GET("/", func(c *gin.Context) {
@@ -71,33 +161,127 @@ var command = cobra.Command{
 				Message: "Welcome to User Service",
 			})
 		})
-		router.Use(func(c *gin.Context) {
-			c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-			c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT")
-			c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, x-service-name, x-api-key, x-request-at")
-			c.Next()
-		})
+		checker := health.NewChecker(time.Duration(config.Get().Health.Timeout)*time.Second, checks...)
+		metricsServer := serveMetrics(router, checker)
+		router.GET("/healthz", health.Liveness)
+		router.GET("/readyz", checker.Readiness)
+		if local, ok := fileStorage.(*storage.LocalStorage); ok {
+			router.Static("/static", local.Directory())
+		}
+		router.Use(middlewares.CORS())
 
 		lmt := tollbooth.NewLimiter(
-			config.Config.RateLimiterMaxRequest,
+			config.Get().RateLimiterMaxRequest,
 			&limiter.ExpirableOptions{
-				DefaultExpirationTTL: time.Duration(config.Config.RateLimiterTimeSecond) * time.Second,
+				DefaultExpirationTTL: time.Duration(config.Get().RateLimiterTimeSecond) * time.Second,
 			},
 		)
+		config.Subscribe(func(prev, next *config.AppConfig) {
+			if prev.RateLimiterMaxRequest != next.RateLimiterMaxRequest || prev.RateLimiterTimeSecond != next.RateLimiterTimeSecond {
+				// Buckets already handed out keep their rate until they expire.
+				lmt.SetMax(next.RateLimiterMaxRequest)
+				lmt.SetTokenBucketExpirationTTL(time.Duration(next.RateLimiterTimeSecond) * time.Second)
+				logrus.Infof("rate limiter changed to %v requests per second", next.RateLimiterMaxRequest)
+			}
+		})
 		router.Use(middlewares.RateLimiter(lmt))
+		router.Use(middlewares.AuditImpersonation(service))
 
 		group := router.Group("/api/v1")
-		route := routes.NewRouteRegistry(controller, group)
+		route := routes.NewRouteRegistry(controller, service, group)
 		route.Serve()
 
-		port := fmt.Sprintf(":%d", config.Config.Port)
-		router.Run(port)
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		go config.Watch(ctx)
+		go config.RenewLeases(ctx)
+
+		errs := make(chan error, 2)
+		server := newServer(fmt.Sprintf(":%d", config.Get().Port), router)
+		listen(server, "http server", errs)
+		if metricsServer != nil {
+			listen(metricsServer, "metrics server", errs)
+		}
+
+		select {
+		case <-ctx.Done():
+			logrus.Info("shutdown signal received, draining connections")
+		case err := <-errs:
+			logrus.Errorf("server stopped unexpectedly: %v", err)
+		}
+
+		checker.Drain()
+		if ctx.Err() != nil {
+			// A second signal now kills the process instead of waiting.
+			stop()
+			drainDelay := time.Duration(config.Get().Server.DrainDelay) * time.Second
+			logrus.Infof("readiness is failing, closing listeners in %s", drainDelay)
+			time.Sleep(drainDelay)
+		}
+
+		serverTimeout := seconds(config.Get().Server.ShutdownTimeout, defaultShutdownTimeout)
+		steps := []shutdownStep{
+			{name: "http server", timeout: serverTimeout, run: server.Shutdown},
+		}
+		if metricsServer != nil {
+			steps = append(steps, shutdownStep{name: "metrics server", timeout: serverTimeout, run: metricsServer.Shutdown})
+		}
+		steps = append(steps,
+			shutdownStep{name: "telemetry", run: shutdownTelemetry},
+			shutdownStep{name: "database", run: func(context.Context) error { return sqlDB.Close() }},
+		)
+		steps = append(steps, closeReplicas...)
+		shutdown(steps...)
 	},
 }
 
-func Run() {
-	err := command.Execute()
-	if err != nil {
-		panic(err)
+func reloadLogLevel(prev, next *config.AppConfig) {
+	if prev.LogLevel != next.LogLevel {
+		logger.Init(next.LogLevel)
+		logrus.Infof("log level changed to %s", next.LogLevel)
+	}
+}
+
+// rotateJwtKey keeps the previous secret verifying for as long as the tokens
+// it signed can live.
+func rotateJwtKey(prev, next *config.AppConfig) {
+	if prev.JwtSecretKey == next.JwtSecretKey {
+		return
+	}
+
+	lifetime := max(
+		prev.JwtExpirationTime,
+		prev.ImpersonationExpirationTime,
+		next.JwtExpirationTime,
+		next.ImpersonationExpirationTime,
+	)
+	jwtkey.Rotate(next.JwtSecretKey, time.Duration(lifetime)*time.Minute)
+	logrus.Info("jwt signing key rotated")
+}
+
+// serveMetrics returns a dedicated server for /metrics and the detailed
+// readiness report when an admin port is configured. Otherwise both are
+// mounted on the public router behind the metrics token and nil is returned.
+func serveMetrics(router *gin.Engine, checker *health.Checker) *http.Server {
+	cfg := config.Get().Metrics
+	switch {
+	case cfg.Port > 0:
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler())
+		mux.HandleFunc("/readyz", checker.Details)
+		return newServer(net.JoinHostPort(cfg.Address, strconv.Itoa(cfg.Port)), mux)
+	case cfg.Token != "":
+		router.GET("/metrics", middlewares.MetricsToken(cfg.Token), gin.WrapH(metrics.Handler()))
+		router.GET("/readyz/details", middlewares.MetricsToken(cfg.Token), gin.WrapF(checker.Details))
+	default:
+		logrus.Warn("metrics are disabled, set metrics.port or metrics.token to expose them")
 	}
+
+	return nil
+}
+
+func init() {
+	serveCommand.Flags().BoolVar(&skipMigrations, "skip-migrations", false, "do not migrate the schema on boot")
+	serveCommand.Flags().BoolVar(&skipSeed, "skip-seed", false, "do not run the seeders on boot")
 }
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+	"user-service/database/migrations"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	confirmDown bool
+	downSteps   int
+)
+
+var migrateCommand = &cobra.Command{
+	Use:   "migrate",
+	Short: "Manage the database schema",
+}
+
+var migrateUpCommand = &cobra.Command{
+	Use:   "up",
+	Short: "Apply the schema",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		loadConfig()
+		db := openDatabase(cmd.Context())
+
+		err := migrations.Up(cmd.Context(), db)
+		if err != nil {
+			return err
+		}
+
+		logrus.Info("migrations applied")
+		return nil
+	},
+}
+
+var migrateDownCommand = &cobra.Command{
+	Use:   "down",
+	Short: "Revert the latest applied migrations",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !confirmDown {
+			return errors.New("migrate down can drop data, pass --yes to confirm")
+		}
+
+		loadConfig()
+		db := openDatabase(cmd.Context())
+
+		err := migrations.Down(cmd.Context(), db, downSteps)
+		if err != nil {
+			return err
+		}
+
+		logrus.Info("migrations rolled back")
+		return nil
+	},
+}
+
+var migrateStatusCommand = &cobra.Command{
+	Use:   "status",
+	Short: "Show which migrations are applied",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		loadConfig()
+		db := openDatabase(cmd.Context())
+
+		statuses, err := migrations.GetStatus(cmd.Context(), db)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.Applied {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+
+		return w.Flush()
+	},
+}
+
+var migrateVerifyCommand = &cobra.Command{
+	Use:   "verify",
+	Short: "Check that the migrated schema matches the models",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		loadConfig()
+		db := openDatabase(cmd.Context())
+
+		issues, err := migrations.Verify(cmd.Context(), db)
+		if err != nil {
+			return err
+		}
+
+		for _, issue := range issues {
+			fmt.Println(issue)
+		}
+
+		if len(issues) > 0 {
+			return fmt.Errorf("schema differs from the models in %d places", len(issues))
+		}
+
+		logrus.Info("schema matches the models")
+		return nil
+	},
+}
+
+func init() {
+	migrateDownCommand.Flags().BoolVar(&confirmDown, "yes", false, "confirm reverting migrations")
+	migrateDownCommand.Flags().IntVar(&downSteps, "steps", 1, "number of migrations to revert")
+	migrateCommand.AddCommand(migrateUpCommand, migrateDownCommand, migrateStatusCommand, migrateVerifyCommand)
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	"user-service/common/logger"
+	"user-service/config"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+// rootCommand starts the server when no subcommand is given, so existing
+// deployments that run the bare binary keep working.
+var rootCommand = &cobra.Command{
+	Use:           "user-service",
+	Short:         "User service",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Run:           serveCommand.Run,
+}
+
+func init() {
+	rootCommand.Flags().AddFlagSet(serveCommand.Flags())
+	rootCommand.AddCommand(
+		serveCommand,
+		migrateCommand,
+		seedCommand,
+		createAdminCommand,
+		configCommand,
+		healthcheckCommand,
+	)
+}
+
+// loadConfig reads the environment and configuration shared by every
+// subcommand.
+func loadConfig() {
+	_ = godotenv.Load()
+	config.Init()
+	logger.Init(config.Get().LogLevel)
+
+	loc, err := time.LoadLocation(os.Getenv("TIMEZONE"))
+	if err != nil {
+		panic(err)
+	}
+
+	time.Local = loc
+}
+
+func openDatabase(ctx context.Context) *gorm.DB {
+	db, err := config.InitDatabase(ctx)
+	if err != nil {
+		panic(err)
+	}
+
+	return db
+}
+
+func Run() {
+	// A stop signal cancels the command context, so waiting on the database
+	// or a migration does not outlive it. The capture ends with the first
+	// signal, so a second one kills the process.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	context.AfterFunc(ctx, stop)
+	err := rootCommand.ExecuteContext(ctx)
+	stop()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
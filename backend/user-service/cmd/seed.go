@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"user-service/config"
+	"user-service/database/seeders"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var seedOptions seeders.Options
+
+var seedCommand = &cobra.Command{
+	Use:   "seed",
+	Short: "Run the database seeders",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		loadConfig()
+		seeder := seeders.NewSeederRegistry(openDatabase(cmd.Context()), config.Get().AppEnv)
+
+		err := seeder.Run(cmd.Context(), seedOptions)
+		if err != nil {
+			return err
+		}
+
+		logrus.Info("seeding finished")
+		return nil
+	},
+}
+
+func init() {
+	seedCommand.Flags().StringVar(&seedOptions.Only, "only", "", "run a single seeder: roles or users")
+	seedCommand.Flags().BoolVar(&seedOptions.DryRun, "dry-run", false, "show what would change without writing it")
+}
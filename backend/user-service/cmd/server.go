@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+	"user-service/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultReadTimeout       = 30 * time.Second
+	defaultReadHeaderTimeout = 5 * time.Second
+	defaultWriteTimeout      = 30 * time.Second
+	defaultIdleTimeout       = 60 * time.Second
+	defaultShutdownTimeout   = 20 * time.Second
+	// cleanupTimeout bounds the steps after the servers, such as flushing
+	// telemetry and closing pools.
+	cleanupTimeout = 5 * time.Second
+)
+
+func seconds(value int, fallback time.Duration) time.Duration {
+	if value <= 0 {
+		return fallback
+	}
+
+	return time.Duration(value) * time.Second
+}
+
+// newServer applies the configured timeouts so slow clients cannot hold
+// connections open indefinitely.
+func newServer(address string, handler http.Handler) *http.Server {
+	cfg := config.Get().Server
+
+	return &http.Server{
+		Addr:              address,
+		Handler:           handler,
+		ReadTimeout:       seconds(cfg.ReadTimeout, defaultReadTimeout),
+		ReadHeaderTimeout: seconds(cfg.ReadHeaderTimeout, defaultReadHeaderTimeout),
+		WriteTimeout:      seconds(cfg.WriteTimeout, defaultWriteTimeout),
+		IdleTimeout:       seconds(cfg.IdleTimeout, defaultIdleTimeout),
+	}
+}
+
+// listen serves in the background and reports a failure to start or a crash
+// on errs. A clean Shutdown is not reported.
+func listen(server *http.Server, name string, errs chan<- error) {
+	go func() {
+		logrus.Infof("%s listening on %s", name, server.Addr)
+		err := server.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errs <- fmt.Errorf("%s: %w", name, err)
+		}
+	}()
+}
+
+type shutdownStep struct {
+	name    string
+	timeout time.Duration
+	run     func(context.Context) error
+}
+
+// shutdown runs every step in order, each within its own timeout, so a slow
+// drain cannot use up the time left to flush telemetry or close the database
+// pool. A step that fails is logged and the rest still run.
+func shutdown(steps ...shutdownStep) {
+	for _, step := range steps {
+		timeout := step.timeout
+		if timeout <= 0 {
+			timeout = cleanupTimeout
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		start := time.Now()
+		err := step.run(ctx)
+		cancel()
+		if err != nil {
+			logrus.Errorf("shutdown step %s failed: %v", step.name, err)
+			continue
+		}
+
+		logrus.Infof("shutdown step %s finished in %s", step.name, time.Since(start))
+	}
+}
@@ -1,12 +1,13 @@
 package customerror
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
+	"user-service/common/logger"
 
 	"github.com/go-playground/validator/v10"
-	"github.com/sirupsen/logrus"
 )
 
 type ValidationResponse struct {
@@ -61,8 +62,9 @@ func ErrValidationResponse(err error) []ValidationResponse {
 	return validationResponses
 }
 
-func WrapError(err error) error {
-	logrus.Error("error: %v", err)
+// WrapError logs err with the request fields of ctx and returns it.
+func WrapError(ctx context.Context, err error) error {
+	logger.FromContext(ctx).Errorf("error: %v", err)
 
 	return err
 }
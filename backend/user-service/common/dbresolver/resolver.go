@@ -0,0 +1,61 @@
+package dbresolver
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"user-service/common/logger"
+	"user-service/common/metrics"
+
+	"gorm.io/gorm"
+)
+
+// PrimaryName labels the primary in metrics and logs, so no replica may use
+// it.
+const PrimaryName = "primary"
+
+// Target is a named connection pool.
+type Target struct {
+	Name string
+	DB   *gorm.DB
+}
+
+// Resolver spreads reads over the read replicas and sends everything else to
+// the primary. Without replicas every read goes to the primary.
+type Resolver struct {
+	primary  *gorm.DB
+	replicas []Target
+	next     atomic.Uint64
+}
+
+func NewResolver(primary *gorm.DB, replicas []Target) *Resolver {
+	return &Resolver{
+		primary:  primary,
+		replicas: replicas,
+	}
+}
+
+func (r *Resolver) Primary() *gorm.DB {
+	return r.primary
+}
+
+// Read runs query against the next replica in turn. The primary is used when
+// the request has already written, since a replica may not have caught up
+// yet, and when the replica fails for any reason other than a missing record
+// or a cancelled request.
+func (r *Resolver) Read(ctx context.Context, query func(*gorm.DB) error) error {
+	if len(r.replicas) == 0 || Written(ctx) {
+		return query(r.primary)
+	}
+
+	replica := r.replicas[(r.next.Add(1)-1)%uint64(len(r.replicas))]
+	err := query(replica.DB)
+	if err == nil || errors.Is(err, gorm.ErrRecordNotFound) || ctx.Err() != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Warnf("replica %s failed, falling back to the primary: %v", replica.Name, err)
+	metrics.ReplicaFallbacks.WithLabelValues(replica.Name).Inc()
+
+	return query(r.primary)
+}
@@ -0,0 +1,55 @@
+package dbresolver
+
+import (
+	"context"
+	"sync/atomic"
+	"user-service/constants"
+
+	"gorm.io/gorm"
+)
+
+// WithSession returns a context that remembers whether the request has
+// written to the primary, so its later reads can see their own writes.
+func WithSession(ctx context.Context) context.Context {
+	return context.WithValue(ctx, constants.DatabaseSession, &atomic.Bool{})
+}
+
+// MarkWritten pins the rest of the request to the primary. It does nothing
+// for contexts without a session.
+func MarkWritten(ctx context.Context) {
+	if written, ok := ctx.Value(constants.DatabaseSession).(*atomic.Bool); ok {
+		written.Store(true)
+	}
+}
+
+func Written(ctx context.Context) bool {
+	written, ok := ctx.Value(constants.DatabaseSession).(*atomic.Bool)
+	return ok && written.Load()
+}
+
+// RegisterSessionTracking marks the session of every statement that writes
+// through db, whichever repository issued it.
+func RegisterSessionTracking(db *gorm.DB) error {
+	callback := db.Callback()
+
+	err := callback.Create().Before("gorm:create").Register("session:create", markStatement)
+	if err != nil {
+		return err
+	}
+
+	err = callback.Update().Before("gorm:update").Register("session:update", markStatement)
+	if err != nil {
+		return err
+	}
+
+	err = callback.Delete().Before("gorm:delete").Register("session:delete", markStatement)
+	if err != nil {
+		return err
+	}
+
+	return callback.Raw().Before("gorm:raw").Register("session:raw", markStatement)
+}
+
+func markStatement(db *gorm.DB) {
+	MarkWritten(db.Statement.Context)
+}
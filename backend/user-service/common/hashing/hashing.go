@@ -0,0 +1,32 @@
+package hashing
+
+import (
+	"context"
+	"time"
+	"user-service/common/metrics"
+	"user-service/common/telemetry"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Compare and Generate wrap bcrypt with a span and a latency metric, since
+// hashing is deliberately slow and often dominates a request.
+func Compare(ctx context.Context, hash, password []byte) error {
+	_, span := telemetry.Start(ctx, "bcrypt.CompareHashAndPassword")
+	defer span.End()
+	defer observe(metrics.BcryptCompare, time.Now())
+
+	return bcrypt.CompareHashAndPassword(hash, password)
+}
+
+func Generate(ctx context.Context, password []byte, cost int) ([]byte, error) {
+	_, span := telemetry.Start(ctx, "bcrypt.GenerateFromPassword")
+	defer span.End()
+	defer observe(metrics.BcryptGenerate, time.Now())
+
+	return bcrypt.GenerateFromPassword(password, cost)
+}
+
+func observe(operation string, start time.Time) {
+	metrics.BcryptDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,97 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"user-service/config"
+	"user-service/database/migrations"
+
+	"gorm.io/gorm"
+)
+
+func Database(db *sql.DB) Check {
+	return Check{
+		Name:     "database",
+		Critical: true,
+		Run: func(ctx context.Context) (any, error) {
+			err := db.PingContext(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			stats := db.Stats()
+			return map[string]int{
+				"openConnections": stats.OpenConnections,
+				"inUse":           stats.InUse,
+				"idle":            stats.Idle,
+			}, nil
+		},
+	}
+}
+
+// Replica reports a read replica. It is not critical: reads fall back to the
+// primary while it is down.
+func Replica(name string, db *sql.DB) Check {
+	check := Database(db)
+	check.Name = "database:" + name
+	check.Critical = false
+	return check
+}
+
+// Migrations reports the embedded migrations that have not been applied yet.
+func Migrations(db *gorm.DB) Check {
+	return Check{
+		Name:     "migrations",
+		Critical: true,
+		Run: func(ctx context.Context) (any, error) {
+			statuses, err := migrations.GetStatus(ctx, db)
+			if err != nil {
+				return nil, err
+			}
+
+			var pending []string
+			for _, status := range statuses {
+				if !status.Applied {
+					pending = append(pending, fmt.Sprintf("%04d_%s", status.Version, status.Name))
+				}
+			}
+
+			if len(pending) > 0 {
+				return map[string][]string{"pending": pending}, fmt.Errorf("%d migrations are pending", len(pending))
+			}
+
+			return nil, nil
+		},
+	}
+}
+
+// Config reports where the configuration came from and how old it is. It is
+// not critical: stale configuration is worth flagging, not worth restarting.
+func Config(maxAge time.Duration) Check {
+	return Check{
+		Name: "config",
+		Run: func(context.Context) (any, error) {
+			origin := config.GetOrigin()
+			age := time.Since(origin.LoadedAt)
+			details := map[string]any{
+				"source":     origin.Source,
+				"layers":     origin.Layers,
+				"loadedAt":   origin.LoadedAt,
+				"ageSeconds": int64(age.Seconds()),
+			}
+
+			if origin.Source == config.SourceSnapshot {
+				return details, errors.New("consul is unreachable, running from the last known good snapshot")
+			}
+
+			if origin.Source == config.SourceConsul && maxAge > 0 && age > maxAge {
+				return details, fmt.Errorf("configuration is older than %s", maxAge)
+			}
+
+			return details, nil
+		},
+	}
+}
@@ -0,0 +1,170 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+	"user-service/common/response"
+	"user-service/constants"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	StatusUp       = "up"
+	StatusDown     = "down"
+	StatusDegraded = "degraded"
+	StatusDraining = "draining"
+
+	defaultTimeout = 2 * time.Second
+)
+
+// Check is a single dependency probe. A failing check that is not Critical
+// only degrades the report, it does not make the service unready.
+type Check struct {
+	Name     string
+	Critical bool
+	Run      func(context.Context) (any, error)
+}
+
+type Result struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+	Details   any     `json:"details,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+type Checker struct {
+	timeout  time.Duration
+	checks   []Check
+	draining atomic.Bool
+}
+
+func NewChecker(timeout time.Duration, checks ...Check) *Checker {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	return &Checker{
+		timeout: timeout,
+		checks:  checks,
+	}
+}
+
+// Drain makes readiness fail from now on, so load balancers stop routing new
+// requests while in-flight ones finish.
+func (hc *Checker) Drain() {
+	hc.draining.Store(true)
+}
+
+// Run executes every check concurrently, each bounded by the checker timeout.
+func (hc *Checker) Run(ctx context.Context) Report {
+	report := Report{
+		Status: StatusUp,
+		Checks: make([]Result, len(hc.checks)),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range hc.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Checks[i] = hc.run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	for i, result := range report.Checks {
+		if result.Status == StatusUp {
+			continue
+		}
+
+		if hc.checks[i].Critical {
+			report.Status = StatusDown
+		} else if report.Status == StatusUp {
+			report.Status = StatusDegraded
+		}
+	}
+
+	return report
+}
+
+func (hc *Checker) run(ctx context.Context, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, hc.timeout)
+	defer cancel()
+
+	start := time.Now()
+	details, err := check.Run(ctx)
+	result := Result{
+		Name:      check.Name,
+		Status:    StatusUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+		Details:   details,
+	}
+
+	if err != nil {
+		result.Status = StatusDown
+		if !check.Critical {
+			result.Status = StatusDegraded
+		}
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// Liveness only reports that the process is serving requests.
+func Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, response.Response{
+		Status:  constants.Success,
+		Message: StatusUp,
+	})
+}
+
+// Readiness answers 503 while any critical check fails. It is public, so it
+// only reports the overall status; Details has the full report.
+func (hc *Checker) Readiness(c *gin.Context) {
+	code, report := hc.report(c.Request.Context())
+
+	status := constants.Success
+	if code != http.StatusOK {
+		status = constants.Error
+	}
+
+	c.JSON(code, response.Response{
+		Status:  status,
+		Message: report.Status,
+	})
+}
+
+// Details serves the full report, including pool sizes and where the
+// configuration came from, for the admin listener.
+func (hc *Checker) Details(w http.ResponseWriter, r *http.Request) {
+	code, report := hc.report(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+func (hc *Checker) report(ctx context.Context) (int, Report) {
+	if hc.draining.Load() {
+		return http.StatusServiceUnavailable, Report{Status: StatusDraining}
+	}
+
+	report := hc.Run(ctx)
+	if report.Status == StatusDown {
+		return http.StatusServiceUnavailable, report
+	}
+
+	return http.StatusOK, report
+}
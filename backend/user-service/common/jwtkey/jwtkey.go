@@ -0,0 +1,105 @@
+package jwtkey
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+type key struct {
+	id        string
+	secret    []byte
+	expiresAt time.Time
+}
+
+// Ring signs tokens with the current secret. Secrets replaced by Rotate keep
+// verifying until the tokens they signed have expired, so rotating the key
+// does not sign everybody out.
+type Ring struct {
+	mu      sync.RWMutex
+	current key
+	retired []key
+}
+
+var ring = &Ring{}
+
+func newKey(secret string) key {
+	hash := sha256.Sum256([]byte(secret))
+	return key{
+		id:     hex.EncodeToString(hash[:4]),
+		secret: []byte(secret),
+	}
+}
+
+// Set replaces the signing secret without keeping the previous one.
+func Set(secret string) {
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+
+	ring.current = newKey(secret)
+	ring.retired = nil
+}
+
+// Rotate signs new tokens with secret and accepts the previous secret for
+// grace, the lifetime of the longest token it may have signed.
+func Rotate(secret string, grace time.Duration) {
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+
+	next := newKey(secret)
+	if next.id == ring.current.id {
+		return
+	}
+
+	now := time.Now()
+	retired := ring.retired[:0]
+	for _, k := range ring.retired {
+		if k.expiresAt.After(now) && k.id != next.id {
+			retired = append(retired, k)
+		}
+	}
+
+	prev := ring.current
+	prev.expiresAt = now.Add(grace)
+	ring.retired = append(retired, prev)
+	ring.current = next
+}
+
+// Sign signs claims with HS256 and records the key ID in the kid header.
+func Sign(claims jwt.Claims) (string, error) {
+	ring.mu.RLock()
+	current := ring.current
+	ring.mu.RUnlock()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = current.id
+	return token.SignedString(current.secret)
+}
+
+// Keyfunc resolves the secret a token was signed with. Tokens issued before
+// key IDs were introduced carry no kid and are checked against the current
+// secret.
+func Keyfunc(token *jwt.Token) (any, error) {
+	ring.mu.RLock()
+	defer ring.mu.RUnlock()
+
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" || kid == ring.current.id {
+		return ring.current.secret, nil
+	}
+
+	now := time.Now()
+	for _, k := range ring.retired {
+		if k.id == kid && k.expiresAt.After(now) {
+			return k.secret, nil
+		}
+	}
+
+	return nil, ErrUnknownKey
+}
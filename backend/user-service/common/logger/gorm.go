@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+const defaultSlowThreshold = 200 * time.Millisecond
+
+// GormLogger writes GORM output through logrus so queries carry the same
+// request fields as the rest of the request's logs.
+type GormLogger struct {
+	level         gormLogger.LogLevel
+	slowThreshold time.Duration
+}
+
+func NewGormLogger(slowThreshold time.Duration) gormLogger.Interface {
+	if slowThreshold <= 0 {
+		slowThreshold = defaultSlowThreshold
+	}
+
+	return &GormLogger{
+		level:         gormLogger.Warn,
+		slowThreshold: slowThreshold,
+	}
+}
+
+func (gl *GormLogger) LogMode(level gormLogger.LogLevel) gormLogger.Interface {
+	clone := *gl
+	clone.level = level
+	return &clone
+}
+
+func (gl *GormLogger) Info(ctx context.Context, msg string, args ...any) {
+	if gl.level >= gormLogger.Info {
+		FromContext(ctx).Infof(msg, args...)
+	}
+}
+
+func (gl *GormLogger) Warn(ctx context.Context, msg string, args ...any) {
+	if gl.level >= gormLogger.Warn {
+		FromContext(ctx).Warnf(msg, args...)
+	}
+}
+
+func (gl *GormLogger) Error(ctx context.Context, msg string, args ...any) {
+	if gl.level >= gormLogger.Error {
+		FromContext(ctx).Errorf(msg, args...)
+	}
+}
+
+func (gl *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if gl.level <= gormLogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	sql, rows := fc()
+	entry := FromContext(ctx).WithFields(logrus.Fields{
+		"latency_ms": elapsed.Milliseconds(),
+		"rows":       rows,
+		"sql":        sql,
+	})
+
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && gl.level >= gormLogger.Error:
+		entry.WithError(err).Error("query failed")
+	case elapsed > gl.slowThreshold && gl.level >= gormLogger.Warn:
+		entry.Warn("slow query")
+	case gl.level >= gormLogger.Info:
+		entry.Info("query")
+	}
+}
@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"user-service/constants"
+	"user-service/domain/dto"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Init switches the global logger to JSON so every line can be queried by
+// its fields.
+func Init(level string) {
+	logrus.SetFormatter(&logrus.JSONFormatter{})
+	logrus.SetOutput(os.Stdout)
+
+	parsed, err := logrus.ParseLevel(level)
+	if err != nil {
+		parsed = logrus.InfoLevel
+	}
+
+	logrus.SetLevel(parsed)
+}
+
+// FromContext returns a logger carrying the request ID, route and user of the
+// request the context belongs to. Fields that are not known yet are left out.
+func FromContext(ctx context.Context) *logrus.Entry {
+	fields := logrus.Fields{}
+	if ctx == nil {
+		return logrus.WithFields(fields)
+	}
+
+	if requestID, ok := ctx.Value(constants.RequestID).(string); ok {
+		fields["request_id"] = requestID
+	}
+
+	if route, ok := ctx.Value(constants.Route).(string); ok && route != "" {
+		fields["route"] = route
+	}
+
+	if user, ok := ctx.Value(constants.UserLogin).(*dto.UserResponse); ok {
+		fields["user_uuid"] = user.UUID
+	}
+
+	if actor, ok := ctx.Value(constants.Actor).(*dto.Actor); ok {
+		fields["actor_uuid"] = actor.UUID
+	}
+
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		fields["trace_id"] = span.TraceID().String()
+		fields["span_id"] = span.SpanID().String()
+	}
+
+	return logrus.WithContext(ctx).WithFields(fields)
+}
+
+// RequestID returns the request ID stored in the context, if any.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(constants.RequestID).(string)
+	return requestID
+}
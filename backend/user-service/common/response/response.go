@@ -2,6 +2,7 @@ package response
 
 import (
 	"net/http"
+	"user-service/common/logger"
 	"user-service/constants"
 	errConstant "user-service/constants/custom-error"
 
@@ -9,10 +10,11 @@ import (
 )
 
 type Response struct {
-	Status  string  `json:"status"`
-	Message string  `json:"message"`
-	Data    any     `json:"data"`
-	Token   *string `json:"token,omitempty"`
+	Status    string  `json:"status"`
+	Message   string  `json:"message"`
+	Data      any     `json:"data"`
+	Token     *string `json:"token,omitempty"`
+	RequestID string  `json:"requestId,omitempty"`
 }
 
 type ParamHTTPResp struct {
@@ -36,6 +38,9 @@ func HttpResponse(param ParamHTTPResp) {
 		return
 	}
 
+	// Attached so the access log can show the cause next to the status code.
+	_ = param.Gin.Error(param.Err)
+
 	message := errConstant.ErrInternalServer.Error()
 	if param.Message != nil {
 		message = *param.Message
@@ -44,8 +49,9 @@ func HttpResponse(param ParamHTTPResp) {
 	}
 
 	param.Gin.JSON(param.Code, Response{
-		Status:  constants.Error,
-		Message: message,
-		Data:    param.Data,
+		Status:    constants.Error,
+		Message:   message,
+		Data:      param.Data,
+		RequestID: logger.RequestID(param.Gin.Request.Context()),
 	})
 }
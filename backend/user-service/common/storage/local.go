@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"user-service/config"
+)
+
+type LocalStorage struct {
+	directory string
+	baseURL   string
+}
+
+func NewLocalStorage(cfg config.LocalStorage) *LocalStorage {
+	directory := cfg.Directory
+	if directory == "" {
+		directory = "storage"
+	}
+
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = "/static"
+	}
+
+	return &LocalStorage{
+		directory: directory,
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+func (ls *LocalStorage) Directory() string {
+	return ls.directory
+}
+
+func (ls *LocalStorage) path(key string) string {
+	return filepath.Join(ls.directory, filepath.FromSlash(filepath.Clean("/"+key)))
+}
+
+// Put writes to a temporary file first so readers never see a partial file.
+func (ls *LocalStorage) Put(_ context.Context, key string, body io.Reader, _ int64, _ string) error {
+	path := ls.path(key)
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = io.Copy(file, body)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+
+	err = os.Chmod(file.Name(), 0o644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
+
+func (ls *LocalStorage) Delete(_ context.Context, key string) error {
+	err := os.Remove(ls.path(key))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (ls *LocalStorage) URL(key string) string {
+	return ls.baseURL + "/" + strings.TrimPrefix(key, "/")
+}
@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"user-service/config"
+)
+
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+// Storage stores public files such as avatars. Keys are slash separated
+// paths relative to the storage root.
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+func NewStorage(cfg config.Storage) (Storage, error) {
+	switch cfg.Driver {
+	case "", DriverLocal:
+		return NewLocalStorage(cfg.Local), nil
+	case DriverS3:
+		return NewS3Storage(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unsupported storage driver %q", cfg.Driver)
+	}
+}
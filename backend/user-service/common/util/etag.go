@@ -0,0 +1,27 @@
+package util
+
+import (
+	"strconv"
+	"strings"
+)
+
+// FormatETag renders a resource version as a strong entity tag.
+func FormatETag(version uint) string {
+	return strconv.Quote(strconv.FormatUint(uint64(version), 10))
+}
+
+// ParseETag reads the version back from an If-Match value. Weak tags and
+// wildcards are refused because the update must match one exact version.
+func ParseETag(value string) (uint, bool) {
+	value = strings.TrimSpace(value)
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return 0, false
+	}
+
+	version, err := strconv.ParseUint(value[1:len(value)-1], 10, 0)
+	if err != nil {
+		return 0, false
+	}
+
+	return uint(version), true
+}
@@ -0,0 +1,14 @@
+package util
+
+import "strings"
+
+// NormalizeUsername and NormalizeEmail are the single source of truth for how
+// identifiers are stored and compared. Registration, login and the
+// availability check must all go through them.
+func NormalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
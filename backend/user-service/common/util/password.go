@@ -0,0 +1,30 @@
+package util
+
+import (
+	"unicode"
+	"user-service/constants"
+	errConstant "user-service/constants/custom-error"
+)
+
+// CheckPasswordPolicy applies to every path that sets a password.
+func CheckPasswordPolicy(password string) error {
+	if len(password) < constants.PasswordMinLength || len(password) > constants.PasswordMaxLength {
+		return errConstant.ErrPasswordTooWeak
+	}
+
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+
+	if !hasLetter || !hasDigit {
+		return errConstant.ErrPasswordTooWeak
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// GenerateToken returns a random hex encoded token of n bytes. Only its
+// HashToken value should be persisted.
+func GenerateToken(n int) (string, error) {
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// GeneratePassword returns a random password containing both letters and
+// digits, as the password policy requires.
+func GeneratePassword() (string, error) {
+	for {
+		password, err := GenerateToken(12)
+		if err != nil {
+			return "", err
+		}
+
+		if strings.ContainsAny(password, "0123456789") && strings.ContainsAny(password, "abcdef") {
+			return password, nil
+		}
+	}
+}
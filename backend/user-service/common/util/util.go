@@ -1,19 +1,19 @@
 package util
 
 import (
+	"encoding/json"
 	"os"
-	"reflect"
 	"strconv"
+	"sync"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
-	_ "github.com/spf13/viper/remote"
 )
 
 func BindFromJSON(dest any, filename, path string) error {
 	v := viper.New()
 
-	v.SetConfigFile("json")
+	v.SetConfigType("json")
 	v.AddConfigPath(path)
 	v.SetConfigName(filename)
 
@@ -22,7 +22,7 @@ func BindFromJSON(dest any, filename, path string) error {
 		return err
 	}
 
-	err = v.Unmarshal(&dest)
+	err = v.Unmarshal(dest)
 	if err != nil {
 		logrus.Errorf("failed to unmarshal: %v", err)
 		return err
@@ -31,74 +31,83 @@ func BindFromJSON(dest any, filename, path string) error {
 	return nil
 }
 
-func SetEnvFromConsulKV(v *viper.Viper) error {
-	env := make(map[string]any, 0)
-
-	err := v.Unmarshal(&env)
+// consulEnv holds the variables SetEnvFromConsulKV exported, which are the
+// only ones it may overwrite or remove on a later load.
+var consulEnv = struct {
+	sync.Mutex
+	names map[string]bool
+}{names: map[string]bool{}}
+
+// SetEnvFromConsulKV exports the scalar values of a Consul document as
+// environment variables, for settings such as TIMEZONE that are read from the
+// environment. Top level keys keep their name and nested keys are joined with
+// an underscore. Variables that were set before the first load are left alone
+// so the environment keeps precedence over Consul; the ones exported here
+// follow every reload and are removed with their key.
+func SetEnvFromConsulKV(doc map[string]any) error {
+	consulEnv.Lock()
+	defer consulEnv.Unlock()
+
+	exported := map[string]bool{}
+	err := setEnvFromTree("", doc, exported)
 	if err != nil {
-		logrus.Errorf("failed to unmarshal: %v", err)
+		for name := range exported {
+			consulEnv.names[name] = true
+		}
 		return err
 	}
 
-	for k, v := range env {
-		var (
-			valOf = reflect.ValueOf(v)
-			val   string
-		)
-
-		switch valOf.Kind() {
-		case reflect.String:
-			val = valOf.String()
-		case reflect.Int:
-			val = strconv.Itoa(int(valOf.Int()))
-		case reflect.Uint:
-			val = strconv.Itoa(int(valOf.Uint()))
-		case reflect.Float32:
-			val = strconv.Itoa(int(valOf.Float()))
-		case reflect.Float64:
-			val = strconv.Itoa(int(valOf.Float()))
-		case reflect.Bool:
-			val = strconv.FormatBool(valOf.Bool())
-		default:
-			panic("unsupported type")
-		}
-
-		err = os.Setenv(k, val)
-		if err != nil {
-			logrus.Errorf("failed to set env: %v", err)
-			return err
+	for name := range consulEnv.names {
+		if !exported[name] {
+			os.Unsetenv(name)
 		}
 	}
+	consulEnv.names = exported
 
 	return nil
 }
 
-func BindFromConsulKV(dest any, endpoint, path string) error {
-	v := viper.New()
-
-	v.SetConfigType("json")
-	err := v.AddRemoteProvider("consul", endpoint, path)
-	if err != nil {
-		logrus.Errorf("failed to add remote provider: %v", err)
-		return err
-	}
+func setEnvFromTree(prefix string, tree map[string]any, exported map[string]bool) error {
+	for k, v := range tree {
+		name := k
+		if prefix != "" {
+			name = prefix + "_" + k
+		}
 
-	err = v.ReadRemoteConfig()
-	if err != nil {
-		logrus.Errorf("failed to read remote provider: %v", err)
-		return err
-	}
+		var val string
+		switch value := v.(type) {
+		case map[string]any:
+			err := setEnvFromTree(name, value, exported)
+			if err != nil {
+				return err
+			}
+			continue
+		case string:
+			val = value
+		case float64:
+			val = strconv.FormatFloat(value, 'f', -1, 64)
+		case bool:
+			val = strconv.FormatBool(value)
+		case nil:
+			continue
+		default:
+			content, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			val = string(content)
+		}
 
-	err = v.Unmarshal(&dest)
-	if err != nil {
-		logrus.Errorf("failed to unmarshal: %v", err)
-		return err
-	}
+		if _, ok := os.LookupEnv(name); ok && !consulEnv.names[name] {
+			continue
+		}
 
-	err = SetEnvFromConsulKV(v)
-	if err != nil {
-		logrus.Errorf("failed to set env from consul kv: %v", err)
-		return err
+		err := os.Setenv(name, val)
+		if err != nil {
+			logrus.Errorf("failed to set env: %v", err)
+			return err
+		}
+		exported[name] = true
 	}
 
 	return nil
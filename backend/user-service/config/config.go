@@ -1,46 +1,219 @@
 package config
 
 import (
-	"os"
-	"user-service/common/util"
+	"context"
+	"sync/atomic"
+	"time"
+)
 
-	"github.com/sirupsen/logrus"
+const (
+	SourceDefaults = "defaults"
+	SourceFile     = "file"
+	SourceConsul   = "consul"
+	SourceSnapshot = "consul-snapshot"
 )
 
-var Config AppConfig
+// Origin describes where the running configuration came from and when it
+// was read, for the readiness report. Layers lists every layer that was
+// applied, Source the most significant one.
+type Origin struct {
+	Source   string
+	Layers   []string
+	LoadedAt time.Time
+}
+
+var origin atomic.Pointer[Origin]
+
+func GetOrigin() Origin {
+	o := origin.Load()
+	if o == nil {
+		return Origin{}
+	}
+
+	return *o
+}
+
+func setOrigin(layers []string) {
+	origin.Store(&Origin{
+		Source:   sourceOf(layers),
+		Layers:   layers,
+		LoadedAt: time.Now(),
+	})
+}
 
 type AppConfig struct {
-	Port                  int      `json:"port"`
-	AppName               string   `json:"appName"`
-	AppEnv                string   `json:"appEnv"`
-	SignatureKey          string   `json:"signatureKey"`
-	Database              Database `json:"database"`
-	RateLimiterMaxRequest float64  `json:"rateLimiterMaxRequest"`
-	RateLimiterTimeSecond int      `json:"rateLimiterTimeSecond"`
-	JwtSecretKey          string   `json:"jwtSecretKey"`
-	JwtExpirationTime     int      `json:"jwtExpirationTime"`
+	Port                        int             `json:"port" validate:"min=1,max=65535" reload:"restart"`
+	AppName                     string          `json:"appName" validate:"required" reload:"restart"`
+	AppEnv                      string          `json:"appEnv" validate:"required" reload:"restart"`
+	LogLevel                    string          `json:"logLevel" validate:"oneof=trace debug info warn warning error fatal panic"`
+	SignatureKey                string          `json:"signatureKey" validate:"required" redact:"true"`
+	Database                    Database        `json:"database" reload:"restart"`
+	RateLimiterMaxRequest       float64         `json:"rateLimiterMaxRequest" validate:"gt=0"`
+	RateLimiterTimeSecond       int             `json:"rateLimiterTimeSecond" validate:"gt=0"`
+	AvailabilityMaxRequest      float64         `json:"availabilityMaxRequest" validate:"gt=0"`
+	JwtSecretKey                string          `json:"jwtSecretKey" validate:"required" redact:"true"`
+	JwtExpirationTime           int             `json:"jwtExpirationTime" validate:"gt=0"`
+	ImpersonationExpirationTime int             `json:"impersonationExpirationTime" validate:"gt=0"`
+	InvitationExpirationTime    int             `json:"invitationExpirationTime" validate:"gt=0"`
+	EmailChangeExpirationTime   int             `json:"emailChangeExpirationTime" validate:"gt=0"`
+	EmailChangeRevertTime       int             `json:"emailChangeRevertTime" validate:"gt=0"`
+	FrontendURL                 string          `json:"frontendUrl" validate:"omitempty,url"`
+	InternalService             InternalService `json:"internalService" reload:"restart"`
+	Storage                     Storage         `json:"storage" reload:"restart"`
+	AvatarMaxSize               int64           `json:"avatarMaxSize" validate:"gt=0"`
+	Telemetry                   Telemetry       `json:"telemetry" reload:"restart"`
+	Metrics                     Metrics         `json:"metrics" reload:"restart"`
+	Health                      Health          `json:"health" reload:"restart"`
+	Server                      Server          `json:"server" reload:"restart"`
+	Cors                        Cors            `json:"cors"`
+	Consul                      Consul          `json:"consul" reload:"restart"`
+	Secrets                     Secrets         `json:"secrets" reload:"restart"`
 }
 
+// Database describes the primary. MaxLifetimeConnection, MaxIdleTime and
+// ConnectTimeout are in seconds, SlowQueryThreshold and StatementTimeout in
+// milliseconds. A zero StatementTimeout keeps the server default.
 type Database struct {
-	Host                  string `json:"host"`
-	Port                  int    `json:"port"`
-	Name                  string `json:"name"`
-	Username              string `json:"username"`
-	Password              string `json:"password"`
-	MaxOpenConnection     int    `json:"maxOpenConnection"`
-	MaxLifetimeConnection int    `json:"maxLifetimeConnection"`
-	MaxIdleConnection     int    `json:"maxIdleConnection"`
-	MaxIdleTime           int    `json:"maxIdleTime"`
+	Host                  string            `json:"host" validate:"required"`
+	Port                  int               `json:"port" validate:"min=1,max=65535"`
+	Name                  string            `json:"name" validate:"required"`
+	Username              string            `json:"username" validate:"required"`
+	Password              string            `json:"password" redact:"true"`
+	MaxOpenConnection     int               `json:"maxOpenConnection" validate:"gt=0"`
+	MaxLifetimeConnection int               `json:"maxLifetimeConnection" validate:"gte=0"`
+	MaxIdleConnection     int               `json:"maxIdleConnection" validate:"gte=0"`
+	MaxIdleTime           int               `json:"maxIdleTime" validate:"gte=0"`
+	SlowQueryThreshold    int               `json:"slowQueryThreshold" validate:"gt=0"`
+	SslMode               string            `json:"sslMode" validate:"oneof=disable allow prefer require verify-ca verify-full"`
+	SslRootCert           string            `json:"sslRootCert"`
+	SslCert               string            `json:"sslCert"`
+	SslKey                string            `json:"sslKey"`
+	ApplicationName       string            `json:"applicationName"`
+	SearchPath            string            `json:"searchPath"`
+	StatementTimeout      int               `json:"statementTimeout" validate:"gte=0"`
+	ConnectTimeout        int               `json:"connectTimeout" validate:"gte=0"`
+	ConnectAttempts       int               `json:"connectAttempts" validate:"gt=0"`
+	Replicas              []DatabaseReplica `json:"replicas" validate:"dive"`
+}
+
+// DatabaseReplica is a read-only copy of the primary. It shares the primary's
+// credentials, database name and pool settings.
+type DatabaseReplica struct {
+	Name string `json:"name"`
+	Host string `json:"host" validate:"required"`
+	Port int    `json:"port" validate:"min=1,max=65535"`
+}
+
+type Telemetry struct {
+	Exporter    string  `json:"exporter" validate:"oneof=none otlp stdout"`
+	Endpoint    string  `json:"endpoint"`
+	Insecure    bool    `json:"insecure"`
+	SampleRatio float64 `json:"sampleRatio" validate:"gte=0,lte=1"`
+}
+
+// Metrics serves /metrics on Address:Port when Port is set. Address defaults
+// to loopback; set it to 0.0.0.0 to let a scraper outside the host in.
+type Metrics struct {
+	Address string `json:"address"`
+	Port    int    `json:"port" validate:"min=0,max=65535"`
+	Token   string `json:"token" redact:"true"`
 }
 
+// Server holds the HTTP server timeouts in seconds. On SIGTERM readiness
+// fails for DrainDelay before the listeners close, so load balancers stop
+// routing first, and ShutdownTimeout bounds how long in-flight requests may
+// take to drain.
+type Server struct {
+	ReadTimeout       int `json:"readTimeout" validate:"gt=0"`
+	ReadHeaderTimeout int `json:"readHeaderTimeout" validate:"gt=0"`
+	WriteTimeout      int `json:"writeTimeout" validate:"gt=0"`
+	IdleTimeout       int `json:"idleTimeout" validate:"gt=0"`
+	DrainDelay        int `json:"drainDelay" validate:"gte=0"`
+	ShutdownTimeout   int `json:"shutdownTimeout" validate:"gt=0"`
+}
+
+// Health tunes the readiness checks. Both values are in seconds.
+type Health struct {
+	Timeout      int `json:"timeout" validate:"gt=0"`
+	ConfigMaxAge int `json:"configMaxAge" validate:"gte=0"`
+}
+
+type Cors struct {
+	AllowedOrigins []string `json:"allowedOrigins" validate:"min=1,dive,required"`
+}
+
+// Consul locates the configuration key, which may hold a JSON document and
+// nested keys. Timeout and WatchInterval are in seconds. WatchInterval is how
+// long a blocking query waits for a change, and how long to pause after a
+// failed one; zero disables live reload. The last document read is kept at
+// SnapshotPath to boot from while Consul is unreachable.
+type Consul struct {
+	Address       string `json:"address"`
+	Key           string `json:"key"`
+	Token         string `json:"token" redact:"true"`
+	Timeout       int    `json:"timeout" validate:"gt=0"`
+	WatchInterval int    `json:"watchInterval" validate:"gte=0"`
+	SnapshotPath  string `json:"snapshotPath"`
+}
+
+// Secrets configures the providers behind secret://<provider>/<path>#<field>
+// references, which any string setting may hold. FileDir is where relative
+// file references are read from.
+type Secrets struct {
+	FileDir string `json:"fileDir"`
+	Vault   Vault  `json:"vault"`
+}
+
+// Vault reads KV version 2 secrets through secret://vault/ and leased secrets,
+// such as database credentials, through secret://vault-dynamic/. Leases are
+// renewed in the background; settings that need a restart keep the secret
+// they started with. Timeout is in seconds. The VAULT_ADDR, VAULT_TOKEN and
+// VAULT_NAMESPACE variables are used when these are empty.
+type Vault struct {
+	Address   string `json:"address" validate:"omitempty,url"`
+	Token     string `json:"token" redact:"true"`
+	Namespace string `json:"namespace"`
+	Timeout   int    `json:"timeout" validate:"gt=0"`
+}
+
+type InternalService struct {
+	Notification Notification `json:"notification"`
+}
+
+type Notification struct {
+	Host         string `json:"host" validate:"omitempty,url"`
+	SignatureKey string `json:"signatureKey" redact:"true"`
+}
+
+type Storage struct {
+	Driver string       `json:"driver" validate:"oneof=local s3"`
+	Local  LocalStorage `json:"local"`
+	S3     S3Storage    `json:"s3"`
+}
+
+type LocalStorage struct {
+	Directory string `json:"directory"`
+	BaseURL   string `json:"baseUrl"`
+}
+
+type S3Storage struct {
+	Endpoint  string `json:"endpoint"`
+	Region    string `json:"region"`
+	Bucket    string `json:"bucket"`
+	AccessKey string `json:"accessKey" redact:"true"`
+	SecretKey string `json:"secretKey" redact:"true"`
+	UseSSL    bool   `json:"useSsl"`
+	PublicURL string `json:"publicUrl"`
+}
+
+// Init loads the configuration and stops the process when it is invalid.
 func Init() {
-	err := util.BindFromJSON(&Config, "config", ".")
+	cfg, layers, err := Load()
 	if err != nil {
-		logrus.Infof("failed to bind config: %v", err)
-
-		err = util.BindFromConsulKV(Config, os.Getenv("CONSUL_HTTP_URL"), os.Getenv("CONSUL_HTTP_KEY"))
-		if err != nil {
-			panic(err)
-		}
+		panic(err)
 	}
+
+	current.Store(cfg)
+	setOrigin(layers)
+	leases.commit(context.Background())
 }
@@ -0,0 +1,267 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrConsulKeyNotFound = errors.New("consul key not found")
+
+// KVProvider fetches the configuration document stored in a key value store.
+// Wait blocks until the document changes past index, or until wait passes,
+// and returns the index the store is at.
+type KVProvider interface {
+	Fetch(ctx context.Context) (map[string]any, error)
+	Wait(ctx context.Context, index uint64, wait time.Duration) (uint64, error)
+}
+
+// NewProvider builds the provider used for the Consul layer. It is a variable
+// so the store can be replaced by a fake.
+var NewProvider = func(cfg Consul) KVProvider {
+	return NewConsulProvider(cfg)
+}
+
+type ConsulProvider struct {
+	client  *http.Client
+	address string
+	key     string
+	token   string
+}
+
+func NewConsulProvider(cfg Consul) *ConsulProvider {
+	address := strings.TrimSuffix(cfg.Address, "/")
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+
+	return &ConsulProvider{
+		client:  &http.Client{Timeout: time.Duration(cfg.Timeout) * time.Second},
+		address: address,
+		key:     strings.Trim(cfg.Key, "/"),
+		token:   cfg.Token,
+	}
+}
+
+type kvEntry struct {
+	Key   string
+	Value []byte
+}
+
+// Fetch reads the key and everything below it. The key itself may hold a JSON
+// document and nested keys such as <key>/database/host override its fields.
+func (cp *ConsulProvider) Fetch(ctx context.Context) (map[string]any, error) {
+	entries, _, err := cp.get(ctx, cp.client, url.Values{"recurse": {"true"}})
+	if err != nil {
+		return nil, err
+	}
+
+	return buildTree(cp.key, entries)
+}
+
+// Wait runs a blocking query, which Consul answers as soon as anything below
+// the key changes. A deleted key counts as a change.
+func (cp *ConsulProvider) Wait(ctx context.Context, index uint64, wait time.Duration) (uint64, error) {
+	// Consul adds up to wait/16 of jitter, so the client timeout cannot apply.
+	ctx, cancel := context.WithTimeout(ctx, wait+wait/16+cp.client.Timeout)
+	defer cancel()
+
+	_, index, err := cp.get(ctx, http.DefaultClient, url.Values{
+		"recurse": {"true"},
+		"index":   {strconv.FormatUint(index, 10)},
+		"wait":    {fmt.Sprintf("%ds", int(wait.Seconds()))},
+	})
+	if err != nil && !errors.Is(err, ErrConsulKeyNotFound) {
+		return 0, err
+	}
+	if index == 0 {
+		return 0, errors.New("consul did not return an index")
+	}
+
+	return index, nil
+}
+
+func (cp *ConsulProvider) get(ctx context.Context, client *http.Client, query url.Values) ([]kvEntry, uint64, error) {
+	segments := strings.Split(cp.key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/kv/%s?%s", cp.address, strings.Join(segments, "/"), query.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if cp.token != "" {
+		req.Header.Set("X-Consul-Token", cp.token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	index, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, index, fmt.Errorf("%w: %s", ErrConsulKeyNotFound, cp.key)
+	case resp.StatusCode != http.StatusOK:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, 0, fmt.Errorf("consul responded %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var entries []kvEntry
+	err = json.NewDecoder(resp.Body).Decode(&entries)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return entries, index, nil
+}
+
+func buildTree(key string, entries []kvEntry) (map[string]any, error) {
+	// Sorting puts the document stored at the key before its nested keys.
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+
+	root := map[string]any{}
+	for _, entry := range entries {
+		if entry.Key != key && !strings.HasPrefix(entry.Key, key+"/") {
+			continue
+		}
+
+		path := strings.Trim(strings.TrimPrefix(entry.Key, key), "/")
+		if entry.Value == nil {
+			continue
+		}
+
+		value := parseValue(entry.Value)
+		if path == "" {
+			doc, ok := value.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("consul key %s must hold a JSON object", key)
+			}
+			merge(root, doc)
+			continue
+		}
+
+		setPath(root, strings.Split(path, "/"), value)
+	}
+
+	return root, nil
+}
+
+// parseValue decodes JSON objects, lists and quoted strings and keeps anything
+// else as the string it was stored as. decodeDocument converts such strings to
+// the type of the setting, so a value such as 12345 stays a string for string
+// settings.
+func parseValue(raw []byte) any {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) > 0 && strings.ContainsRune("{[\"", rune(trimmed[0])) {
+		var value any
+		if json.Unmarshal(trimmed, &value) == nil {
+			return value
+		}
+	}
+
+	return string(trimmed)
+}
+
+func setPath(tree map[string]any, path []string, value any) {
+	for _, segment := range path[:len(path)-1] {
+		child, ok := tree[segment].(map[string]any)
+		if !ok {
+			child = map[string]any{}
+			tree[segment] = child
+		}
+		tree = child
+	}
+
+	last := path[len(path)-1]
+	if doc, ok := value.(map[string]any); ok {
+		if existing, ok := tree[last].(map[string]any); ok {
+			merge(existing, doc)
+			return
+		}
+	}
+	tree[last] = value
+}
+
+func merge(dst, src map[string]any) {
+	for k, v := range src {
+		if doc, ok := v.(map[string]any); ok {
+			if existing, ok := dst[k].(map[string]any); ok {
+				merge(existing, doc)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
+
+// decodeDocument overlays doc onto cfg. Keys match the JSON names without
+// regard to case, like the file layers. String values are parsed like
+// environment variables when the setting is not a string.
+func decodeDocument(cfg *AppConfig, doc map[string]any) error {
+	return decodeTree(reflect.ValueOf(cfg).Elem(), doc, "")
+}
+
+func decodeTree(value reflect.Value, tree map[string]any, prefix string) error {
+	for key, item := range tree {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		field, ok := fieldByJSONName(value, key)
+		if !ok {
+			continue
+		}
+
+		if child, ok := item.(map[string]any); ok && field.Kind() == reflect.Struct {
+			err := decodeTree(field, child, path)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		var err error
+		if raw, ok := item.(string); ok && field.Kind() != reflect.String {
+			err = setValue(field, raw)
+		} else {
+			var content []byte
+			content, err = json.Marshal(item)
+			if err == nil {
+				err = json.Unmarshal(content, field.Addr().Interface())
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+func fieldByJSONName(value reflect.Value, name string) (reflect.Value, bool) {
+	for i := 0; i < value.NumField(); i++ {
+		if strings.EqualFold(jsonName(value.Type().Field(i)), name) {
+			return value.Field(i), true
+		}
+	}
+
+	return reflect.Value{}, false
+}
@@ -0,0 +1,277 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type fakeProvider struct {
+	doc map[string]any
+	err error
+}
+
+func (fp *fakeProvider) Fetch(context.Context) (map[string]any, error) {
+	return fp.doc, fp.err
+}
+
+func (fp *fakeProvider) Wait(context.Context, uint64, time.Duration) (uint64, error) {
+	return 0, fp.err
+}
+
+func useProvider(t *testing.T, provider KVProvider) {
+	t.Helper()
+
+	previous := NewProvider
+	NewProvider = func(Consul) KVProvider { return provider }
+	t.Cleanup(func() { NewProvider = previous })
+}
+
+func consulServer(t *testing.T, handler http.HandlerFunc) Consul {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return Consul{Address: server.URL, Key: "user-service", Token: "secret-token", Timeout: 5}
+}
+
+func writeEntries(t *testing.T, w http.ResponseWriter, entries []kvEntry) {
+	t.Helper()
+
+	w.Header().Set("X-Consul-Index", "42")
+	err := json.NewEncoder(w).Encode(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConsulProviderMergesNestedKeysOverDocument(t *testing.T) {
+	cfg := consulServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/kv/user-service" || r.URL.Query().Get("recurse") != "true" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		if token := r.Header.Get("X-Consul-Token"); token != "secret-token" {
+			t.Errorf("X-Consul-Token = %q, want secret-token", token)
+		}
+
+		writeEntries(t, w, []kvEntry{
+			{Key: "user-service/database/password", Value: []byte("12345")},
+			{Key: "user-service/database/port", Value: []byte("6432")},
+			{Key: "user-service/cors/allowedOrigins", Value: []byte(`["https://a.example","https://b.example"]`)},
+			{Key: "user-service/appName", Value: []byte("true")},
+			{Key: "user-service/database/", Value: nil},
+			{Key: "user-service", Value: []byte(`{"jwtSecretKey":"from-doc","database":{"host":"db","name":"users","password":"doc"}}`)},
+			{Key: "user-service-other/appName", Value: []byte("ignored")},
+		})
+	})
+
+	doc, err := NewConsulProvider(cfg).Fetch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := Defaults()
+	err = decodeDocument(&got, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.JwtSecretKey != "from-doc" {
+		t.Errorf("JwtSecretKey = %q, want from-doc", got.JwtSecretKey)
+	}
+	if got.Database.Host != "db" || got.Database.Name != "users" {
+		t.Errorf("Database = %s/%s, want db/users", got.Database.Host, got.Database.Name)
+	}
+	if got.Database.Password != "12345" {
+		t.Errorf("Database.Password = %q, want the nested key 12345", got.Database.Password)
+	}
+	if got.Database.Port != 6432 {
+		t.Errorf("Database.Port = %d, want 6432", got.Database.Port)
+	}
+	if got.AppName != "true" {
+		t.Errorf("AppName = %q, want true", got.AppName)
+	}
+	if len(got.Cors.AllowedOrigins) != 2 || got.Cors.AllowedOrigins[1] != "https://b.example" {
+		t.Errorf("Cors.AllowedOrigins = %v", got.Cors.AllowedOrigins)
+	}
+	if got.Database.SlowQueryThreshold != Defaults().Database.SlowQueryThreshold {
+		t.Errorf("Database.SlowQueryThreshold = %d, want the default", got.Database.SlowQueryThreshold)
+	}
+}
+
+func TestConsulProviderRejectsInvalidValues(t *testing.T) {
+	cfg := consulServer(t, func(w http.ResponseWriter, r *http.Request) {
+		writeEntries(t, w, []kvEntry{
+			{Key: "user-service/database/port", Value: []byte("not-a-port")},
+		})
+	})
+
+	doc, err := NewConsulProvider(cfg).Fetch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := Defaults()
+	err = decodeDocument(&got, doc)
+	if err == nil {
+		t.Fatal("expected an error for a non numeric port")
+	}
+}
+
+func TestConsulProviderMissingKey(t *testing.T) {
+	cfg := consulServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	_, err := NewConsulProvider(cfg).Fetch(context.Background())
+	if !errors.Is(err, ErrConsulKeyNotFound) {
+		t.Fatalf("err = %v, want ErrConsulKeyNotFound", err)
+	}
+}
+
+func TestConsulProviderEmptyPrefix(t *testing.T) {
+	cfg := consulServer(t, func(w http.ResponseWriter, r *http.Request) {
+		writeEntries(t, w, []kvEntry{})
+	})
+
+	doc, err := NewConsulProvider(cfg).Fetch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc) != 0 {
+		t.Fatalf("doc = %v, want an empty document", doc)
+	}
+}
+
+func TestConsulProviderRejectsNonObjectDocument(t *testing.T) {
+	cfg := consulServer(t, func(w http.ResponseWriter, r *http.Request) {
+		writeEntries(t, w, []kvEntry{{Key: "user-service", Value: []byte("plain")}})
+	})
+
+	_, err := NewConsulProvider(cfg).Fetch(context.Background())
+	if err == nil {
+		t.Fatal("expected an error for a document that is not an object")
+	}
+}
+
+func TestConsulProviderWaitUsesBlockingQuery(t *testing.T) {
+	cfg := consulServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("index") != "7" || query.Get("wait") != "30s" {
+			t.Errorf("unexpected blocking query %s", r.URL.RawQuery)
+		}
+		if token := r.Header.Get("X-Consul-Token"); token != "secret-token" {
+			t.Errorf("X-Consul-Token = %q, want secret-token", token)
+		}
+
+		writeEntries(t, w, []kvEntry{})
+	})
+
+	index, err := NewConsulProvider(cfg).Wait(context.Background(), 7, 30*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index != 42 {
+		t.Fatalf("index = %d, want 42", index)
+	}
+}
+
+func TestLoadConsulWritesSnapshotAndFallsBack(t *testing.T) {
+	// loadConsul exports the document to the environment; setting the
+	// variable first keeps it from leaking out of the test.
+	t.Setenv("appName", "")
+
+	consul := Consul{
+		Address:      "http://consul.invalid",
+		Key:          "user-service",
+		Timeout:      1,
+		SnapshotPath: filepath.Join(t.TempDir(), "consul", "snapshot.json"),
+	}
+
+	useProvider(t, &fakeProvider{doc: map[string]any{"appName": "from-consul"}})
+
+	cfg := Defaults()
+	layer, err := loadConsul(&cfg, consul)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if layer != SourceConsul+":user-service" {
+		t.Errorf("layer = %q", layer)
+	}
+
+	useProvider(t, &fakeProvider{err: errors.New("connection refused")})
+
+	cfg = Defaults()
+	layer, err = loadConsul(&cfg, consul)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if layer != SourceSnapshot+":"+consul.SnapshotPath {
+		t.Errorf("layer = %q, want the snapshot", layer)
+	}
+	if cfg.AppName != "from-consul" {
+		t.Errorf("AppName = %q, want the value from the snapshot", cfg.AppName)
+	}
+}
+
+func TestLoadConsulFailsWithoutSnapshot(t *testing.T) {
+	consul := Consul{
+		Address:      "http://consul.invalid",
+		Key:          "user-service",
+		Timeout:      1,
+		SnapshotPath: filepath.Join(t.TempDir(), "missing.json"),
+	}
+
+	useProvider(t, &fakeProvider{err: errors.New("connection refused")})
+
+	cfg := Defaults()
+	_, err := loadConsul(&cfg, consul)
+	if err == nil {
+		t.Fatal("expected an error when neither Consul nor a snapshot is available")
+	}
+}
+
+func TestLoadConsulFollowsReloadsInTheEnvironment(t *testing.T) {
+	t.Setenv("appName", "")
+	t.Cleanup(func() { os.Unsetenv("CONSUL_TEST_TIMEZONE") })
+
+	consul := Consul{Address: "http://consul.invalid", Key: "user-service", Timeout: 1}
+
+	for _, timezone := range []string{"Asia/Jakarta", "UTC"} {
+		useProvider(t, &fakeProvider{doc: map[string]any{
+			"appName":              "from-consul",
+			"CONSUL_TEST_TIMEZONE": timezone,
+		}})
+
+		cfg := Defaults()
+		_, err := loadConsul(&cfg, consul)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := os.Getenv("CONSUL_TEST_TIMEZONE"); got != timezone {
+			t.Errorf("CONSUL_TEST_TIMEZONE = %q, want %q", got, timezone)
+		}
+		if got := os.Getenv("appName"); got != "" {
+			t.Errorf("appName = %q, want the environment to keep precedence", got)
+		}
+	}
+
+	useProvider(t, &fakeProvider{doc: map[string]any{}})
+	cfg := Defaults()
+	_, err := loadConsul(&cfg, consul)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := os.LookupEnv("CONSUL_TEST_TIMEZONE"); ok {
+		t.Error("CONSUL_TEST_TIMEZONE must be removed with its key")
+	}
+}
@@ -1,26 +1,119 @@
 package config
 
 import (
+	"cmp"
+	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/url"
+	"strconv"
 	"time"
+	"user-service/common/dbresolver"
+	"user-service/common/logger"
 
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/sirupsen/logrus"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/plugin/opentelemetry/tracing"
 )
 
-func InitDatabase() (*gorm.DB, error) {
-	cfg := Config
-	encodedPassword := url.QueryEscape(cfg.Database.Password)
-	uri := fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=disable",
-		cfg.Database.Username,
-		encodedPassword,
-		cfg.Database.Host,
-		cfg.Database.Port,
-		cfg.Database.Name,
+// Postgres often comes up after the service in docker-compose, so the first
+// connection is retried with a doubling delay.
+const (
+	connectBackoff    = 500 * time.Millisecond
+	maxConnectBackoff = 15 * time.Second
+)
+
+// InitDatabase opens the primary. It gives up when ctx is done, so a stop
+// signal is not held up by the connection retries.
+func InitDatabase(ctx context.Context) (*gorm.DB, error) {
+	cfg := Get().Database
+	return openDatabase(ctx, cfg, cfg.Host, cfg.Port)
+}
+
+// InitReplicas opens one pool per configured read replica. A replica that
+// cannot be reached is left out with an error in the log, since reads fall
+// back to the primary anyway. Each replica gets a single attempt so a missing
+// one does not hold up the boot.
+func InitReplicas(ctx context.Context) []dbresolver.Target {
+	cfg := Get().Database
+	replicaCfg := cfg
+	replicaCfg.ConnectAttempts = 1
+
+	targets := make([]dbresolver.Target, 0, len(cfg.Replicas))
+	for i, replica := range cfg.Replicas {
+		name := cfg.replicaName(i)
+		db, err := openDatabase(ctx, replicaCfg, replica.Host, replica.Port)
+		if err != nil {
+			logrus.Errorf("replica %s is left out, reads use the remaining databases: %v", name, err)
+			continue
+		}
+
+		targets = append(targets, dbresolver.Target{Name: name, DB: db})
+	}
+
+	return targets
+}
+
+// replicaName names the replica at index i, replica-1, replica-2 and so on
+// when it has no name of its own.
+func (d Database) replicaName(i int) string {
+	if d.Replicas[i].Name != "" {
+		return d.Replicas[i].Name
+	}
+
+	return fmt.Sprintf("replica-%d", i+1)
+}
+
+func openDatabase(ctx context.Context, cfg Database, host string, port int) (*gorm.DB, error) {
+	dsn := dataSourceName(cfg, host, port)
+
+	var (
+		db  *gorm.DB
+		err error
 	)
+	backoff := connectBackoff
+	for attempt := 1; ; attempt++ {
+		db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
+			Logger: logger.NewGormLogger(time.Duration(cfg.SlowQueryThreshold) * time.Millisecond),
+		})
+		if err == nil {
+			break
+		}
+
+		if db != nil {
+			// The ping failed, but the pool was opened.
+			if sqlDB, dbErr := db.DB(); dbErr == nil {
+				sqlDB.Close()
+			}
+		}
+
+		// A bad option, certificate or password will not fix itself.
+		var parseErr *pgconn.ParseConfigError
+		if errors.As(err, &parseErr) || authenticationFailed(err) {
+			return nil, err
+		}
+
+		if attempt >= cfg.ConnectAttempts {
+			return nil, fmt.Errorf("database %s:%d is unreachable after %d attempts: %w", host, port, attempt, err)
+		}
 
-	db, err := gorm.Open(postgres.Open(uri), &gorm.Config{})
+		logrus.Warnf("database %s:%d is unreachable (attempt %d of %d), retrying in %s: %v", host, port, attempt, cfg.ConnectAttempts, backoff, err)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("database %s:%d: gave up connecting: %w", host, port, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxConnectBackoff)
+	}
+
+	err = db.Use(tracing.NewPlugin(
+		tracing.WithDBName(cfg.Name),
+		tracing.WithoutQueryVariables(),
+		tracing.WithoutMetrics(),
+	))
 	if err != nil {
 		return nil, err
 	}
@@ -30,10 +123,64 @@ func InitDatabase() (*gorm.DB, error) {
 		return nil, err
 	}
 
-	sqlDB.SetMaxIdleConns(cfg.Database.MaxIdleConnection)
-	sqlDB.SetMaxOpenConns(cfg.Database.MaxOpenConnection)
-	sqlDB.SetConnMaxIdleTime(time.Duration(cfg.Database.MaxIdleTime) * time.Second)
-	sqlDB.SetConnMaxLifetime(time.Duration(cfg.Database.MaxLifetimeConnection) * time.Second)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConnection)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConnection)
+	sqlDB.SetConnMaxIdleTime(time.Duration(cfg.MaxIdleTime) * time.Second)
+	sqlDB.SetConnMaxLifetime(time.Duration(cfg.MaxLifetimeConnection) * time.Second)
 
 	return db, nil
 }
+
+// authenticationFailed reports whether the server rejected the credentials:
+// SQLSTATE 28P01 is a wrong password and 28000 a role that may not log in.
+func authenticationFailed(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == "28P01" || pgErr.Code == "28000")
+}
+
+// dataSourceName builds the connection URL. Unset options are left out so the
+// driver and server defaults apply.
+func dataSourceName(cfg Database, host string, port int) string {
+	query := url.Values{}
+	query.Set("sslmode", cfg.SslMode)
+	for key, value := range map[string]string{
+		"sslrootcert":      cfg.SslRootCert,
+		"sslcert":          cfg.SslCert,
+		"sslkey":           cfg.SslKey,
+		"application_name": cmp.Or(cfg.ApplicationName, Get().AppName),
+		"search_path":      cfg.SearchPath,
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	if cfg.StatementTimeout > 0 {
+		query.Set("statement_timeout", strconv.Itoa(cfg.StatementTimeout))
+	}
+	if cfg.ConnectTimeout > 0 {
+		query.Set("connect_timeout", strconv.Itoa(cfg.ConnectTimeout))
+	}
+
+	uri := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.Username, cfg.Password),
+		Host:     net.JoinHostPort(host, strconv.Itoa(port)),
+		Path:     "/" + cfg.Name,
+		RawQuery: query.Encode(),
+	}
+
+	return uri.String()
+}
+
+// decodeEnv reads a replica given as host:port, which is how
+// DATABASE_REPLICAS lists them.
+func (dr *DatabaseReplica) decodeEnv(raw string) error {
+	host, port, err := net.SplitHostPort(raw)
+	if err != nil {
+		return err
+	}
+
+	dr.Host = host
+	dr.Port, err = strconv.Atoi(port)
+	return err
+}
@@ -0,0 +1,73 @@
+package config
+
+import "user-service/constants"
+
+// Defaults is the lowest configuration layer. Secrets and connection details
+// have no default and must be provided.
+func Defaults() AppConfig {
+	return AppConfig{
+		Port:                        8001,
+		AppName:                     "user-service",
+		AppEnv:                      constants.AppEnvDevelopment,
+		LogLevel:                    "info",
+		RateLimiterMaxRequest:       10,
+		RateLimiterTimeSecond:       60,
+		AvailabilityMaxRequest:      1,
+		JwtExpirationTime:           60,
+		ImpersonationExpirationTime: 15,
+		InvitationExpirationTime:    72,
+		EmailChangeExpirationTime:   24,
+		EmailChangeRevertTime:       168,
+		AvatarMaxSize:               5 << 20,
+		Database: Database{
+			Host:               "localhost",
+			Port:               5432,
+			MaxOpenConnection:  10,
+			MaxIdleConnection:  5,
+			MaxIdleTime:        300,
+			SlowQueryThreshold: 200,
+			SslMode:            "disable",
+			ConnectTimeout:     5,
+			ConnectAttempts:    8,
+		},
+		Storage: Storage{
+			Driver: "local",
+			Local: LocalStorage{
+				Directory: "storage",
+				BaseURL:   "/static",
+			},
+		},
+		Telemetry: Telemetry{
+			Exporter:    "none",
+			SampleRatio: 1,
+		},
+		Metrics: Metrics{
+			Address: "127.0.0.1",
+		},
+		Health: Health{
+			Timeout: 2,
+		},
+		Cors: Cors{
+			AllowedOrigins: []string{"*"},
+		},
+		Consul: Consul{
+			Timeout:       5,
+			WatchInterval: 30,
+			SnapshotPath:  "tmp/consul-snapshot.json",
+		},
+		Secrets: Secrets{
+			FileDir: defaultSecretDir,
+			Vault: Vault{
+				Timeout: 5,
+			},
+		},
+		Server: Server{
+			ReadTimeout:       30,
+			ReadHeaderTimeout: 5,
+			WriteTimeout:      30,
+			IdleTimeout:       60,
+			DrainDelay:        5,
+			ShutdownTimeout:   20,
+		},
+	}
+}
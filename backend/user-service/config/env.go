@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// applyEnv overrides cfg with environment variables named after the JSON path
+// of each field, e.g. database.maxOpenConnection is DATABASE_MAX_OPEN_CONNECTION.
+// Lists are comma separated; read replicas are given as host:port.
+func applyEnv(cfg *AppConfig) (bool, error) {
+	return applyEnvTo(reflect.ValueOf(cfg).Elem(), "")
+}
+
+func applyEnvTo(value reflect.Value, prefix string) (bool, error) {
+	applied := false
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		path := jsonName(field)
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+
+		if field.Type.Kind() == reflect.Struct {
+			ok, err := applyEnvTo(value.Field(i), path)
+			if err != nil {
+				return false, err
+			}
+			applied = applied || ok
+			continue
+		}
+
+		name := envName(path)
+		raw, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+
+		err := setValue(value.Field(i), raw)
+		if err != nil {
+			return false, fmt.Errorf("config: %s: %w", name, err)
+		}
+		applied = true
+	}
+
+	return applied, nil
+}
+
+// envDecoder is implemented by list elements that are not plain strings.
+type envDecoder interface {
+	decodeEnv(raw string) error
+}
+
+func setValue(field reflect.Value, raw string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int, reflect.Int64:
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(parsed)
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(parsed)
+	case reflect.Slice:
+		values := reflect.MakeSlice(field.Type(), 0, 0)
+		for _, value := range strings.Split(raw, ",") {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+
+			elem := reflect.New(field.Type().Elem())
+			if decoder, ok := elem.Interface().(envDecoder); ok {
+				err := decoder.decodeEnv(value)
+				if err != nil {
+					return err
+				}
+			} else if elem.Elem().Kind() == reflect.String {
+				elem.Elem().SetString(value)
+			} else {
+				return fmt.Errorf("unsupported type %s", field.Type())
+			}
+			values = reflect.Append(values, elem.Elem())
+		}
+		field.Set(values)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(parsed)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Kind())
+	}
+
+	return nil
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+
+	return name
+}
+
+// envName turns a JSON path such as storage.s3.useSsl into STORAGE_S3_USE_SSL.
+func envName(path string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(path, ".") {
+		if b.Len() > 0 {
+			b.WriteByte('_')
+		}
+
+		runes := []rune(part)
+		for i, r := range runes {
+			if i > 0 && unicode.IsUpper(r) && !unicode.IsUpper(runes[i-1]) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToUpper(r))
+		}
+	}
+
+	return b.String()
+}
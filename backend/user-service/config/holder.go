@@ -0,0 +1,111 @@
+package config
+
+import (
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Subscriber is notified after a reload has replaced the configuration.
+type Subscriber func(prev, next *AppConfig)
+
+var (
+	current     atomic.Pointer[AppConfig]
+	mu          sync.Mutex
+	subscribers []Subscriber
+
+	// pending holds the restart-only changes already warned about, so each
+	// one is reported once rather than on every reload.
+	pending = map[string]any{}
+)
+
+// Get returns the running configuration. The value is shared and must not be
+// modified, callers that keep settings around should Subscribe to changes.
+func Get() *AppConfig {
+	cfg := current.Load()
+	if cfg == nil {
+		return &AppConfig{}
+	}
+
+	return cfg
+}
+
+func Subscribe(subscriber Subscriber) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	subscribers = append(subscribers, subscriber)
+}
+
+// Apply swaps in next and notifies the subscribers. Changes to fields tagged
+// reload:"restart" are dropped with a warning, since the components using them
+// are only built at startup. An invalid configuration is rejected as a whole.
+func Apply(next *AppConfig) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	prev := Get()
+	changed := restartFields(prev, next)
+	for _, change := range changed {
+		if ignored, ok := pending[change.name]; ok && reflect.DeepEqual(ignored, change.value) {
+			continue
+		}
+
+		pending[change.name] = change.value
+		logrus.Warnf("config: ignoring change to %s, restart the service to apply it", change.name)
+	}
+	for name := range pending {
+		if !slices.ContainsFunc(changed, func(change restartChange) bool { return change.name == name }) {
+			delete(pending, name)
+		}
+	}
+
+	if reflect.DeepEqual(prev, next) {
+		return nil
+	}
+
+	err := Validate(next)
+	if err != nil {
+		return err
+	}
+
+	current.Store(next)
+	for _, subscriber := range subscribers {
+		subscriber(prev, next)
+	}
+
+	return nil
+}
+
+// restartChange is a change to a field that cannot change at runtime.
+type restartChange struct {
+	name  string
+	value any
+}
+
+// restartFields restores the fields of next that cannot change at runtime
+// from prev and returns those that differed with the values next had.
+func restartFields(prev, next *AppConfig) []restartChange {
+	var changed []restartChange
+
+	prevValue, nextValue := reflect.ValueOf(prev).Elem(), reflect.ValueOf(next).Elem()
+	for i := 0; i < nextValue.NumField(); i++ {
+		field := nextValue.Type().Field(i)
+		if field.Tag.Get("reload") != "restart" {
+			continue
+		}
+
+		if !reflect.DeepEqual(prevValue.Field(i).Interface(), nextValue.Field(i).Interface()) {
+			changed = append(changed, restartChange{
+				name:  jsonName(field),
+				value: nextValue.Field(i).Interface(),
+			})
+			nextValue.Field(i).Set(prevValue.Field(i))
+		}
+	}
+
+	return changed
+}
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+	"user-service/common/util"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+const (
+	configName = "config"
+	configPath = "."
+)
+
+// Load resolves and validates the configuration.
+func Load() (*AppConfig, []string, error) {
+	cfg, layers, err := Resolve()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = Validate(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cfg, layers, nil
+}
+
+// Resolve builds the configuration from defaults, config.json, the profile
+// file for the app env (config.<appEnv>.json), Consul and finally environment
+// variables, each layer overriding the ones before it. secret:// references
+// are resolved last. The applied layers are returned for reporting.
+func Resolve() (*AppConfig, []string, error) {
+	cfg := Defaults()
+	layers := []string{SourceDefaults}
+
+	loaded, err := loadFile(&cfg, configName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if loaded {
+		layers = append(layers, SourceFile+":"+configName+".json")
+	}
+
+	appEnv := cfg.AppEnv
+	if env := os.Getenv(envName("appEnv")); env != "" {
+		appEnv = env
+	}
+
+	if appEnv != "" {
+		profile := configName + "." + appEnv
+		loaded, err = loadFile(&cfg, profile)
+		if err != nil {
+			return nil, nil, err
+		}
+		if loaded {
+			layers = append(layers, SourceFile+":"+profile+".json")
+		}
+	}
+
+	consul, err := consulSettings(cfg.Consul)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if consul.Address != "" && consul.Key != "" {
+		layer, err := loadConsul(&cfg, consul)
+		if err != nil {
+			return nil, nil, err
+		}
+		layers = append(layers, layer)
+	}
+
+	applied, err := applyEnv(&cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	if applied {
+		layers = append(layers, "env")
+	}
+
+	err = resolveSecrets(&cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &cfg, layers, nil
+}
+
+// loadFile overlays <name>.json onto cfg. A missing file is not an error.
+func loadFile(cfg *AppConfig, name string) (bool, error) {
+	err := util.BindFromJSON(cfg, name, configPath)
+	if errors.As(err, &viper.ConfigFileNotFoundError{}) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("config: %s.json: %w", name, err)
+	}
+
+	return true, nil
+}
+
+// consulSettings applies the environment to the Consul settings early, since
+// they are needed to read the Consul layer. The legacy CONSUL_HTTP_* variables
+// are honoured when the new ones are not set.
+func consulSettings(consul Consul) (Consul, error) {
+	_, err := applyEnvTo(reflect.ValueOf(&consul).Elem(), "consul")
+	if err != nil {
+		return consul, err
+	}
+
+	for _, legacy := range []struct {
+		field *string
+		name  string
+	}{
+		{&consul.Address, "CONSUL_HTTP_URL"},
+		{&consul.Key, "CONSUL_HTTP_KEY"},
+		{&consul.Token, "CONSUL_HTTP_TOKEN"},
+	} {
+		if *legacy.field == "" {
+			*legacy.field = os.Getenv(legacy.name)
+		}
+	}
+
+	return consul, nil
+}
+
+// loadConsul overlays the Consul document onto cfg and refreshes the
+// snapshot. When Consul cannot be reached the snapshot is used instead.
+func loadConsul(cfg *AppConfig, consul Consul) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(consul.Timeout)*time.Second)
+	defer cancel()
+
+	layer := SourceConsul + ":" + consul.Key
+	doc, err := NewProvider(consul).Fetch(ctx)
+	if err != nil {
+		if consul.SnapshotPath == "" {
+			return "", fmt.Errorf("config: consul %s: %w", consul.Key, err)
+		}
+
+		var savedAt time.Time
+		var snapErr error
+		doc, savedAt, snapErr = readSnapshot(consul.SnapshotPath)
+		if snapErr != nil {
+			return "", fmt.Errorf("config: consul %s: %w, and no snapshot is usable: %v", consul.Key, err, snapErr)
+		}
+
+		logrus.Warnf("config: consul %s is unavailable (%v), using the snapshot saved at %s", consul.Key, err, savedAt.Format(time.RFC3339))
+		layer = SourceSnapshot + ":" + consul.SnapshotPath
+	} else if consul.SnapshotPath != "" {
+		err = writeSnapshot(consul.SnapshotPath, doc)
+		if err != nil {
+			logrus.Warnf("config: failed to write consul snapshot: %v", err)
+		}
+	}
+
+	err = decodeDocument(cfg, doc)
+	if err != nil {
+		return "", fmt.Errorf("config: consul %s: %w", consul.Key, err)
+	}
+
+	err = util.SetEnvFromConsulKV(doc)
+	if err != nil {
+		return "", err
+	}
+
+	return layer, nil
+}
+
+func sourceOf(layers []string) string {
+	source := SourceDefaults
+	for _, layer := range layers {
+		switch {
+		case strings.HasPrefix(layer, SourceSnapshot):
+			return SourceSnapshot
+		case strings.HasPrefix(layer, SourceConsul):
+			return SourceConsul
+		case strings.HasPrefix(layer, SourceFile):
+			source = SourceFile
+		}
+	}
+
+	return source
+}
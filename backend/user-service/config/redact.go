@@ -0,0 +1,24 @@
+package config
+
+import "reflect"
+
+const redacted = "[REDACTED]"
+
+// Redacted returns a copy of cfg with every field tagged redact:"true"
+// masked. Empty secrets stay empty so a missing value is still visible.
+func Redacted(cfg AppConfig) AppConfig {
+	redact(reflect.ValueOf(&cfg).Elem())
+	return cfg
+}
+
+func redact(value reflect.Value) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		switch {
+		case field.Kind() == reflect.Struct:
+			redact(field)
+		case value.Type().Field(i).Tag.Get("redact") == "true" && field.String() != "":
+			field.SetString(redacted)
+		}
+	}
+}
@@ -0,0 +1,387 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const secretScheme = "secret://"
+
+var ErrUnknownSecretProvider = errors.New("unknown secret provider")
+
+// Secret is a resolved reference. Leased secrets carry the lease to renew.
+type Secret struct {
+	Value         string
+	LeaseID       string
+	LeaseDuration time.Duration
+	Renewable     bool
+}
+
+// SecretProvider resolves the path and optional field of a reference such as
+// secret://<provider>/<path>#<field>.
+type SecretProvider interface {
+	Get(ctx context.Context, path, field string) (*Secret, error)
+}
+
+// LeaseRenewer is implemented by providers whose secrets expire. Renew returns
+// the new lease duration, Revoke gives up a lease that is no longer used.
+type LeaseRenewer interface {
+	Renew(ctx context.Context, leaseID string, increment time.Duration) (time.Duration, error)
+	Revoke(ctx context.Context, leaseID string) error
+}
+
+// NewSecretProviders builds the providers available to secret:// references,
+// keyed by name. It is a variable so the backends can be replaced by fakes.
+var NewSecretProviders = func(cfg Secrets) map[string]SecretProvider {
+	providers := map[string]SecretProvider{
+		"file": NewFileSecretProvider(cfg.FileDir),
+	}
+	if cfg.Vault.Address != "" {
+		providers["vault"] = NewVaultSecretProvider(cfg.Vault)
+		providers["vault-dynamic"] = NewVaultDynamicSecretProvider(cfg.Vault)
+	}
+
+	return providers
+}
+
+// resolveSecrets replaces every secret:// reference in cfg with its value and
+// stages the leases, which are renewed once the configuration is applied. The
+// secrets settings themselves may only reference files.
+func resolveSecrets(cfg *AppConfig) error {
+	settings, err := secretSettings(cfg.Secrets)
+	if err != nil {
+		return err
+	}
+	cfg.Secrets = settings
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(settings.Vault.Timeout)*time.Second)
+	defer cancel()
+
+	providers := NewSecretProviders(settings)
+	var resolved []lease
+	err = walkSecrets(reflect.ValueOf(cfg).Elem(), "", func(path, ref string) (string, error) {
+		name, secretPath, field, err := parseSecretRef(ref)
+		if err != nil {
+			return "", err
+		}
+
+		provider, ok := providers[name]
+		if !ok {
+			return "", fmt.Errorf("%w %q", ErrUnknownSecretProvider, name)
+		}
+
+		secret, err := provider.Get(ctx, secretPath, field)
+		if err != nil {
+			return "", err
+		}
+
+		renewer, ok := provider.(LeaseRenewer)
+		leased := ok && secret.Renewable && secret.LeaseID != ""
+		if leased && !slices.ContainsFunc(resolved, func(l lease) bool { return l.id == secret.LeaseID }) {
+			resolved = append(resolved, newLease(path, secret, renewer))
+		}
+
+		return secret.Value, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	leases.stage(resolved)
+	return nil
+}
+
+// secretSettings applies the standard VAULT_* variables when the settings
+// are not configured and resolves file references in them, so the Vault token
+// can be mounted as a file.
+func secretSettings(settings Secrets) (Secrets, error) {
+	for _, fallback := range []struct {
+		field *string
+		name  string
+	}{
+		{&settings.Vault.Address, "VAULT_ADDR"},
+		{&settings.Vault.Token, "VAULT_TOKEN"},
+		{&settings.Vault.Namespace, "VAULT_NAMESPACE"},
+	} {
+		if *fallback.field == "" {
+			*fallback.field = os.Getenv(fallback.name)
+		}
+	}
+
+	files := NewFileSecretProvider(settings.FileDir)
+	err := walkSecrets(reflect.ValueOf(&settings).Elem(), "secrets", func(path, ref string) (string, error) {
+		name, secretPath, field, err := parseSecretRef(ref)
+		if err != nil {
+			return "", err
+		}
+		if name != "file" {
+			return "", fmt.Errorf("only file secrets may be referenced here")
+		}
+
+		secret, err := files.Get(context.Background(), secretPath, field)
+		if err != nil {
+			return "", err
+		}
+
+		return secret.Value, nil
+	})
+
+	return settings, err
+}
+
+// parseSecretRef splits secret://<provider>/<path>#<field>.
+func parseSecretRef(ref string) (string, string, string, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	path := strings.TrimPrefix(u.Path, "/")
+	if u.Host == "" || path == "" {
+		return "", "", "", fmt.Errorf("secret reference must look like secret://<provider>/<path>")
+	}
+
+	return u.Host, path, u.Fragment, nil
+}
+
+// walkSecrets calls resolve for every string field holding a secret://
+// reference and stores the value it returns. The secrets settings are
+// skipped, they are resolved on their own.
+func walkSecrets(value reflect.Value, prefix string, resolve func(path, ref string) (string, error)) error {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		path := jsonName(value.Type().Field(i))
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+
+		switch {
+		case field.Type() == reflect.TypeOf(Secrets{}) && prefix == "":
+			continue
+		case field.Kind() == reflect.Struct:
+			err := walkSecrets(field, path, resolve)
+			if err != nil {
+				return err
+			}
+		case field.Kind() == reflect.String && strings.HasPrefix(field.String(), secretScheme):
+			secret, err := resolve(path, field.String())
+			if err != nil {
+				return fmt.Errorf("config: %s: %w", path, err)
+			}
+			field.SetString(secret)
+		}
+	}
+
+	return nil
+}
+
+// leaseRetry is how long to wait before retrying a failed renewal.
+const leaseRetry = 30 * time.Second
+
+type lease struct {
+	path      string
+	id        string
+	duration  time.Duration
+	renewAt   time.Time
+	expiresAt time.Time
+	renewer   LeaseRenewer
+}
+
+func newLease(path string, secret *Secret, renewer LeaseRenewer) lease {
+	return lease{
+		path:      path,
+		id:        secret.LeaseID,
+		duration:  secret.LeaseDuration,
+		renewAt:   renewAt(secret.LeaseDuration),
+		expiresAt: time.Now().Add(secret.LeaseDuration),
+		renewer:   renewer,
+	}
+}
+
+// renewAt schedules a renewal once two thirds of the lease have passed.
+func renewAt(duration time.Duration) time.Time {
+	return time.Now().Add(duration * 2 / 3)
+}
+
+// restartOnly reports whether the setting at path is only read at startup, so
+// it keeps the secret it was started with.
+func restartOnly(path string) bool {
+	name, _, _ := strings.Cut(path, ".")
+	cfg := reflect.TypeOf(AppConfig{})
+	for i := 0; i < cfg.NumField(); i++ {
+		if jsonName(cfg.Field(i)) == name {
+			return cfg.Field(i).Tag.Get("reload") == "restart"
+		}
+	}
+
+	return false
+}
+
+type leaseSet struct {
+	mu      sync.Mutex
+	leases  []lease
+	staged  []lease
+	started bool
+	changed chan struct{}
+}
+
+var leases = &leaseSet{changed: make(chan struct{}, 1)}
+
+func (ls *leaseSet) stage(resolved []lease) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	ls.staged = resolved
+}
+
+// commit starts renewing the leases of the configuration that was just
+// applied. Settings that need a restart keep the secrets they were started
+// with, so their old leases stay and the new ones are revoked.
+func (ls *leaseSet) commit(ctx context.Context) {
+	ls.mu.Lock()
+	var unused []lease
+	if !ls.started {
+		ls.leases = ls.staged
+		ls.started = true
+	} else {
+		var next []lease
+		for _, l := range ls.leases {
+			if restartOnly(l.path) {
+				next = append(next, l)
+			}
+		}
+		for _, l := range ls.staged {
+			if restartOnly(l.path) {
+				unused = append(unused, l)
+				continue
+			}
+			next = append(next, l)
+		}
+		ls.leases = next
+	}
+	ls.staged = nil
+	ls.mu.Unlock()
+
+	revoke(ctx, unused)
+	select {
+	case ls.changed <- struct{}{}:
+	default:
+	}
+}
+
+// discard revokes the staged leases of a configuration that was not applied.
+func (ls *leaseSet) discard(ctx context.Context) {
+	ls.mu.Lock()
+	unused := ls.staged
+	ls.staged = nil
+	ls.mu.Unlock()
+
+	revoke(ctx, unused)
+}
+
+func revoke(ctx context.Context, unused []lease) {
+	for _, l := range unused {
+		err := l.renewer.Revoke(ctx, l.id)
+		if err != nil {
+			logrus.Warnf("config: failed to revoke the unused lease of %s: %v", l.path, err)
+		}
+	}
+}
+
+func (ls *leaseSet) next() (time.Time, bool) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	var earliest time.Time
+	for _, l := range ls.leases {
+		if earliest.IsZero() || l.renewAt.Before(earliest) {
+			earliest = l.renewAt
+		}
+	}
+
+	return earliest, !earliest.IsZero()
+}
+
+// renewDue renews the leases that are due and reports whether a setting that
+// can be reloaded lost its lease. A setting that needs a restart cannot get a
+// new secret at runtime, its lease is dropped with an error instead.
+func (ls *leaseSet) renewDue(ctx context.Context) bool {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	failed := false
+	now := time.Now()
+	next := ls.leases[:0]
+	for _, l := range ls.leases {
+		if l.renewAt.After(now) {
+			next = append(next, l)
+			continue
+		}
+
+		duration, err := l.renewer.Renew(ctx, l.id, l.duration)
+		if err != nil {
+			if restartOnly(l.path) {
+				logrus.Errorf("config: failed to renew the lease of %s, restart the service before it expires at %s: %v", l.path, l.expiresAt.Format(time.RFC3339), err)
+				continue
+			}
+
+			logrus.Errorf("config: failed to renew the lease of %s: %v", l.path, err)
+			l.renewAt = now.Add(leaseRetry)
+			next = append(next, l)
+			failed = true
+			continue
+		}
+
+		l.duration = duration
+		l.renewAt = renewAt(duration)
+		l.expiresAt = now.Add(duration)
+		next = append(next, l)
+		logrus.Debugf("config: renewed the lease of %s for %s", l.path, duration)
+	}
+	ls.leases = next
+
+	return failed
+}
+
+// RenewLeases keeps leased secrets alive until ctx is done. When the lease of
+// a setting that can be reloaded cannot be renewed, the configuration is
+// resolved again to obtain a fresh secret.
+func RenewLeases(ctx context.Context) {
+	for {
+		wait := time.Hour
+		if at, ok := leases.next(); ok {
+			wait = max(time.Until(at), time.Second)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-leases.changed:
+			timer.Stop()
+			continue
+		case <-timer.C:
+		}
+
+		if leases.renewDue(ctx) {
+			reload()
+		}
+	}
+}
+
+// DiscardLeases revokes the leases of a configuration that was resolved but
+// never applied, such as the one printed by the config command.
+func DiscardLeases(ctx context.Context) {
+	leases.discard(ctx)
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const defaultSecretDir = "/run/secrets"
+
+// FileSecretProvider reads Docker and Kubernetes secrets mounted as files.
+// secret://file/jwt_secret reads <dir>/jwt_secret and secret://file//etc/x
+// reads /etc/x. With a #field the file is parsed as a JSON object.
+type FileSecretProvider struct {
+	dir string
+}
+
+func NewFileSecretProvider(dir string) *FileSecretProvider {
+	if dir == "" {
+		dir = defaultSecretDir
+	}
+
+	return &FileSecretProvider{dir: dir}
+}
+
+func (fp *FileSecretProvider) Get(_ context.Context, path, field string) (*Secret, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(fp.dir, path)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if field == "" {
+		return &Secret{Value: strings.TrimRight(string(content), "\r\n")}, nil
+	}
+
+	var doc map[string]any
+	err = json.Unmarshal(content, &doc)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a JSON object: %w", path, err)
+	}
+
+	value, err := secretField(doc, field)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Secret{Value: value}, nil
+}
+
+func secretField(doc map[string]any, field string) (string, error) {
+	value, ok := doc[field]
+	if !ok {
+		return "", fmt.Errorf("field %q not found", field)
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case float64, bool:
+		return fmt.Sprint(v), nil
+	default:
+		return "", fmt.Errorf("field %q is not a scalar", field)
+	}
+}
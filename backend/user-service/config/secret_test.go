@@ -0,0 +1,323 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func vaultServer(t *testing.T, handler http.HandlerFunc) Vault {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := r.Header.Get("X-Vault-Token"); token != "root" {
+			t.Errorf("X-Vault-Token = %q, want root", token)
+		}
+		if namespace := r.Header.Get("X-Vault-Namespace"); namespace != "team" {
+			t.Errorf("X-Vault-Namespace = %q, want team", namespace)
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	return Vault{Address: server.URL + "/", Token: "root", Namespace: "team", Timeout: 5}
+}
+
+func writeJSON(t *testing.T, w http.ResponseWriter, status int, body any) {
+	t.Helper()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVaultSecretProviderReadsKVVersion2(t *testing.T) {
+	cfg := vaultServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/secret/data/user-service" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+
+		writeJSON(t, w, http.StatusOK, map[string]any{
+			"data": map[string]any{
+				"data":     map[string]any{"value": "default-field", "jwt": "from-field", "port": 5432},
+				"metadata": map[string]any{"version": 3},
+			},
+		})
+	})
+
+	provider := NewVaultSecretProvider(cfg)
+	for field, want := range map[string]string{"": "default-field", "jwt": "from-field", "port": "5432"} {
+		secret, err := provider.Get(context.Background(), "secret/user-service", field)
+		if err != nil {
+			t.Fatalf("field %q: %v", field, err)
+		}
+		if secret.Value != want {
+			t.Errorf("field %q = %q, want %q", field, secret.Value, want)
+		}
+	}
+
+	_, err := provider.Get(context.Background(), "secret/user-service", "missing")
+	if err == nil {
+		t.Error("expected an error for a missing field")
+	}
+}
+
+func TestVaultSecretProviderReportsErrors(t *testing.T) {
+	cfg := vaultServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/plain") {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		writeJSON(t, w, http.StatusForbidden, map[string]any{"errors": []string{"permission denied"}})
+	})
+
+	provider := NewVaultSecretProvider(cfg)
+
+	_, err := provider.Get(context.Background(), "secret/user-service", "")
+	if err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("err = %v, want the error from the body", err)
+	}
+
+	_, err = provider.Get(context.Background(), "secret/plain", "")
+	if err == nil || !strings.Contains(err.Error(), "502") {
+		t.Errorf("err = %v, want the status", err)
+	}
+
+	_, err = provider.Get(context.Background(), "secret", "")
+	if err == nil {
+		t.Error("expected an error for a reference without a path")
+	}
+}
+
+func TestVaultDynamicSecretProviderSharesOneLease(t *testing.T) {
+	reads := 0
+	cfg := vaultServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/database/creds/user-service" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		reads++
+
+		writeJSON(t, w, http.StatusOK, map[string]any{
+			"lease_id":       "database/creds/user-service/abc",
+			"lease_duration": 3600,
+			"renewable":      true,
+			"data":           map[string]any{"username": "v-user", "password": "v-pass"},
+		})
+	})
+
+	provider := NewVaultDynamicSecretProvider(cfg)
+	username, err := provider.Get(context.Background(), "database/creds/user-service", "username")
+	if err != nil {
+		t.Fatal(err)
+	}
+	password, err := provider.Get(context.Background(), "database/creds/user-service", "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if username.Value != "v-user" || password.Value != "v-pass" {
+		t.Errorf("credentials = %s/%s", username.Value, password.Value)
+	}
+	if reads != 1 {
+		t.Errorf("reads = %d, want the fields to share one read", reads)
+	}
+	if !password.Renewable || password.LeaseID != "database/creds/user-service/abc" || password.LeaseDuration != time.Hour {
+		t.Errorf("lease = %+v", password)
+	}
+}
+
+func TestVaultSecretProviderRenewsAndRevokes(t *testing.T) {
+	cfg := vaultServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Method != http.MethodPut || body["lease_id"] != "lease-1" {
+			t.Errorf("unexpected request %s %s %v", r.Method, r.URL.Path, body)
+		}
+
+		switch r.URL.Path {
+		case "/v1/sys/leases/renew":
+			if body["increment"] != float64(600) {
+				t.Errorf("increment = %v, want 600", body["increment"])
+			}
+			writeJSON(t, w, http.StatusOK, map[string]any{"lease_id": "lease-1", "lease_duration": 300})
+		case "/v1/sys/leases/revoke":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	provider := NewVaultDynamicSecretProvider(cfg)
+	duration, err := provider.Renew(context.Background(), "lease-1", 10*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if duration != 5*time.Minute {
+		t.Errorf("duration = %s, want the duration granted by Vault", duration)
+	}
+
+	err = provider.Revoke(context.Background(), "lease-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+type fakeSecrets struct {
+	values  map[string]*Secret
+	renew   error
+	renewed []string
+	revoked []string
+}
+
+func (fs *fakeSecrets) Get(_ context.Context, path, field string) (*Secret, error) {
+	secret, ok := fs.values[path+"#"+field]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+
+	return secret, nil
+}
+
+func (fs *fakeSecrets) Renew(_ context.Context, leaseID string, increment time.Duration) (time.Duration, error) {
+	fs.renewed = append(fs.renewed, leaseID)
+	return increment, fs.renew
+}
+
+func (fs *fakeSecrets) Revoke(_ context.Context, leaseID string) error {
+	fs.revoked = append(fs.revoked, leaseID)
+	return nil
+}
+
+func useSecrets(t *testing.T, providers map[string]SecretProvider) {
+	t.Helper()
+
+	previous, previousLeases := NewSecretProviders, leases
+	NewSecretProviders = func(Secrets) map[string]SecretProvider { return providers }
+	leases = &leaseSet{changed: make(chan struct{}, 1)}
+	t.Cleanup(func() {
+		NewSecretProviders = previous
+		leases = previousLeases
+	})
+}
+
+func TestResolveSecretsReplacesReferences(t *testing.T) {
+	fake := &fakeSecrets{values: map[string]*Secret{
+		"jwt#":          {Value: "jwt-secret"},
+		"db/creds#user": {Value: "v-user", LeaseID: "db-lease", LeaseDuration: time.Hour, Renewable: true},
+		"db/creds#pass": {Value: "v-pass", LeaseID: "db-lease", LeaseDuration: time.Hour, Renewable: true},
+		"signature#key": {Value: "signature"},
+	}}
+	useSecrets(t, map[string]SecretProvider{"fake": fake})
+
+	cfg := Defaults()
+	cfg.JwtSecretKey = "secret://fake/jwt"
+	cfg.SignatureKey = "secret://fake/signature#key"
+	cfg.Database.Username = "secret://fake/db/creds#user"
+	cfg.Database.Password = "secret://fake/db/creds#pass"
+	cfg.Database.Name = "users"
+
+	err := resolveSecrets(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.JwtSecretKey != "jwt-secret" || cfg.SignatureKey != "signature" {
+		t.Errorf("keys = %q, %q", cfg.JwtSecretKey, cfg.SignatureKey)
+	}
+	if cfg.Database.Username != "v-user" || cfg.Database.Password != "v-pass" || cfg.Database.Name != "users" {
+		t.Errorf("database = %+v", cfg.Database)
+	}
+	if len(leases.staged) != 1 || leases.staged[0].path != "database.username" {
+		t.Errorf("staged = %+v, want one lease for the database credentials", leases.staged)
+	}
+}
+
+func TestResolveSecretsRejectsUnknownProviders(t *testing.T) {
+	useSecrets(t, map[string]SecretProvider{})
+
+	cfg := Defaults()
+	cfg.JwtSecretKey = "secret://missing/jwt"
+
+	err := resolveSecrets(&cfg)
+	if !errors.Is(err, ErrUnknownSecretProvider) {
+		t.Fatalf("err = %v, want ErrUnknownSecretProvider", err)
+	}
+}
+
+func TestLeasesKeepRestartOnlySecretsAcrossReloads(t *testing.T) {
+	fake := &fakeSecrets{}
+	useSecrets(t, map[string]SecretProvider{"fake": fake})
+
+	first := []lease{
+		newLease("database.password", &Secret{LeaseID: "db-1", LeaseDuration: time.Hour}, fake),
+		newLease("jwtSecretKey", &Secret{LeaseID: "jwt-1", LeaseDuration: time.Hour}, fake),
+	}
+	leases.stage(first)
+	leases.commit(context.Background())
+
+	leases.stage([]lease{
+		newLease("database.password", &Secret{LeaseID: "db-2", LeaseDuration: time.Hour}, fake),
+		newLease("jwtSecretKey", &Secret{LeaseID: "jwt-2", LeaseDuration: time.Hour}, fake),
+	})
+	leases.commit(context.Background())
+
+	var active []string
+	for _, l := range leases.leases {
+		active = append(active, l.id)
+	}
+	if strings.Join(active, ",") != "db-1,jwt-2" {
+		t.Errorf("active leases = %v, want db-1,jwt-2", active)
+	}
+	if strings.Join(fake.revoked, ",") != "db-2" {
+		t.Errorf("revoked = %v, want the unused database lease", fake.revoked)
+	}
+}
+
+func TestRenewDueReloadsOnlyReloadableSettings(t *testing.T) {
+	fake := &fakeSecrets{renew: errors.New("lease expired")}
+	useSecrets(t, map[string]SecretProvider{"fake": fake})
+
+	due := func(path, id string) lease {
+		l := newLease(path, &Secret{LeaseID: id, LeaseDuration: time.Hour}, fake)
+		l.renewAt = time.Now().Add(-time.Second)
+		return l
+	}
+
+	leases.stage([]lease{due("database.password", "db-1")})
+	leases.commit(context.Background())
+	if leases.renewDue(context.Background()) {
+		t.Error("a restart-only setting must not trigger a reload")
+	}
+	if len(leases.leases) != 0 {
+		t.Errorf("leases = %+v, want the lost lease dropped", leases.leases)
+	}
+
+	leases.leases = []lease{due("jwtSecretKey", "jwt-1")}
+	if !leases.renewDue(context.Background()) {
+		t.Error("a reloadable setting must trigger a reload")
+	}
+	if len(leases.leases) != 1 || !leases.leases[0].renewAt.After(time.Now()) {
+		t.Errorf("leases = %+v, want the lease kept for a retry", leases.leases)
+	}
+
+	fake.renew = nil
+	leases.leases[0].renewAt = time.Now().Add(-time.Second)
+	if leases.renewDue(context.Background()) {
+		t.Error("a renewed lease must not trigger a reload")
+	}
+	if strings.Join(fake.renewed, ",") != "db-1,jwt-1,jwt-1" {
+		t.Errorf("renewed = %v", fake.renewed)
+	}
+}
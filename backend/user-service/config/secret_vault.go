@@ -0,0 +1,183 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultVaultField = "value"
+
+// VaultSecretProvider reads HashiCorp Vault secrets. By default it reads KV
+// version 2: secret://vault/<mount>/<path>#<field> reads <field> of the latest
+// version of <path> in the <mount> engine, the field defaults to "value".
+// The dynamic provider reads leased engines instead, e.g.
+// secret://vault-dynamic/database/creds/<role>#password reads
+// /v1/database/creds/<role>.
+type VaultSecretProvider struct {
+	client    *http.Client
+	address   string
+	token     string
+	namespace string
+	dynamic   bool
+
+	// Every read of a dynamic secret issues new credentials, so fields of the
+	// same path share one read.
+	mu    sync.Mutex
+	reads map[string]*vaultResponse
+}
+
+func NewVaultSecretProvider(cfg Vault) *VaultSecretProvider {
+	return &VaultSecretProvider{
+		client:    &http.Client{Timeout: time.Duration(cfg.Timeout) * time.Second},
+		address:   strings.TrimSuffix(cfg.Address, "/"),
+		token:     cfg.Token,
+		namespace: cfg.Namespace,
+	}
+}
+
+func NewVaultDynamicSecretProvider(cfg Vault) *VaultSecretProvider {
+	vp := NewVaultSecretProvider(cfg)
+	vp.dynamic = true
+	vp.reads = map[string]*vaultResponse{}
+
+	return vp
+}
+
+type vaultResponse struct {
+	LeaseID       string         `json:"lease_id"`
+	LeaseDuration int            `json:"lease_duration"`
+	Renewable     bool           `json:"renewable"`
+	Data          map[string]any `json:"data"`
+	Errors        []string       `json:"errors"`
+}
+
+func (vp *VaultSecretProvider) Get(ctx context.Context, path, field string) (*Secret, error) {
+	if field == "" {
+		field = defaultVaultField
+	}
+
+	if vp.dynamic {
+		return vp.getDynamic(ctx, path, field)
+	}
+
+	mount, secretPath, ok := strings.Cut(path, "/")
+	if !ok || secretPath == "" {
+		return nil, fmt.Errorf("vault reference must look like secret://vault/<mount>/<path>")
+	}
+
+	var resp vaultResponse
+	err := vp.do(ctx, http.MethodGet, fmt.Sprintf("/v1/%s/data/%s", mount, secretPath), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	data, _ := resp.Data["data"].(map[string]any)
+	value, err := secretField(data, field)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Secret{Value: value}, nil
+}
+
+func (vp *VaultSecretProvider) getDynamic(ctx context.Context, path, field string) (*Secret, error) {
+	vp.mu.Lock()
+	defer vp.mu.Unlock()
+
+	resp, ok := vp.reads[path]
+	if !ok {
+		resp = &vaultResponse{}
+		err := vp.do(ctx, http.MethodGet, "/v1/"+path, nil, resp)
+		if err != nil {
+			return nil, err
+		}
+		vp.reads[path] = resp
+	}
+
+	value, err := secretField(resp.Data, field)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Secret{
+		Value:         value,
+		LeaseID:       resp.LeaseID,
+		LeaseDuration: time.Duration(resp.LeaseDuration) * time.Second,
+		Renewable:     resp.Renewable,
+	}, nil
+}
+
+func (vp *VaultSecretProvider) Renew(ctx context.Context, leaseID string, increment time.Duration) (time.Duration, error) {
+	body := map[string]any{
+		"lease_id":  leaseID,
+		"increment": int(increment.Seconds()),
+	}
+
+	var resp vaultResponse
+	err := vp.do(ctx, http.MethodPut, "/v1/sys/leases/renew", body, &resp)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(resp.LeaseDuration) * time.Second, nil
+}
+
+func (vp *VaultSecretProvider) Revoke(ctx context.Context, leaseID string) error {
+	var resp vaultResponse
+	return vp.do(ctx, http.MethodPut, "/v1/sys/leases/revoke", map[string]any{"lease_id": leaseID}, &resp)
+}
+
+func (vp *VaultSecretProvider) do(ctx context.Context, method, path string, body, dest any) error {
+	var reader io.Reader
+	if body != nil {
+		content, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(content)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, vp.address+path, reader)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("X-Vault-Token", vp.token)
+	if vp.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", vp.namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := vp.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(dest)
+	if err != nil && resp.StatusCode == http.StatusOK {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if errResp, ok := dest.(*vaultResponse); ok && len(errResp.Errors) > 0 {
+			return fmt.Errorf("vault responded %s: %s", resp.Status, strings.Join(errResp.Errors, "; "))
+		}
+		return fmt.Errorf("vault responded %s", resp.Status)
+	}
+
+	return nil
+}
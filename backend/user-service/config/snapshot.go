@@ -0,0 +1,82 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type snapshot struct {
+	SavedAt  time.Time      `json:"savedAt"`
+	Document map[string]any `json:"document"`
+}
+
+// writeSnapshot stores the last document read from Consul so the service can
+// boot while Consul is unreachable. It holds secrets and is only readable by
+// the owner. Unchanged documents are not rewritten.
+func writeSnapshot(path string, doc map[string]any) error {
+	previous, _, err := readSnapshot(path)
+	if err == nil && sameDocument(previous, doc) {
+		return nil
+	}
+
+	content, err := json.Marshal(snapshot{SavedAt: time.Now(), Document: doc})
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o700)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".snapshot-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(content)
+	if err == nil {
+		err = tmp.Chmod(0o600)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func readSnapshot(path string) (map[string]any, time.Time, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	var saved snapshot
+	err = json.Unmarshal(content, &saved)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	return saved.Document, saved.SavedAt, nil
+}
+
+func sameDocument(a, b map[string]any) bool {
+	left, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+
+	right, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(left, right)
+}
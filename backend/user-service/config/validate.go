@@ -0,0 +1,114 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"user-service/common/dbresolver"
+	"user-service/constants"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const productionJwtSecretMinLength = 32
+
+// Validate reports every invalid field at once, named by its JSON path so the
+// message points at the key to fix in any layer.
+func Validate(cfg *AppConfig) error {
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return jsonName(field)
+	})
+
+	var problems []string
+	err := validate.Struct(cfg)
+	if err != nil {
+		var validationErrors validator.ValidationErrors
+		if !errors.As(err, &validationErrors) {
+			return err
+		}
+
+		for _, fieldErr := range validationErrors {
+			problems = append(problems, describe(fieldErr))
+		}
+	}
+
+	problems = append(problems, crossFieldProblems(cfg)...)
+	if len(problems) > 0 {
+		return fmt.Errorf("config: invalid configuration:\n  %s", strings.Join(problems, "\n  "))
+	}
+
+	return nil
+}
+
+func describe(fieldErr validator.FieldError) string {
+	_, path, _ := strings.Cut(fieldErr.Namespace(), ".")
+
+	switch fieldErr.Tag() {
+	case "required":
+		// List elements have no variable of their own.
+		if strings.Contains(path, "[") {
+			return fmt.Sprintf("%s is required", path)
+		}
+		return fmt.Sprintf("%s is required (env %s)", path, envName(path))
+	case "oneof":
+		return fmt.Sprintf("%s must be one of [%s], got %q", path, fieldErr.Param(), fieldErr.Value())
+	case "url":
+		return fmt.Sprintf("%s must be a URL, got %q", path, fieldErr.Value())
+	case "gt", "gte", "lt", "lte", "min", "max":
+		return fmt.Sprintf("%s must be %s %s, got %v", path, comparisons[fieldErr.Tag()], fieldErr.Param(), fieldErr.Value())
+	default:
+		return fmt.Sprintf("%s failed %s validation", path, fieldErr.Tag())
+	}
+}
+
+var comparisons = map[string]string{
+	"gt":  "greater than",
+	"gte": "at least",
+	"min": "at least",
+	"lt":  "less than",
+	"lte": "at most",
+	"max": "at most",
+}
+
+func crossFieldProblems(cfg *AppConfig) []string {
+	var problems []string
+
+	if cfg.Storage.Driver == "s3" {
+		s3 := cfg.Storage.S3
+		for _, field := range []struct{ path, value string }{
+			{"storage.s3.endpoint", s3.Endpoint},
+			{"storage.s3.bucket", s3.Bucket},
+			{"storage.s3.accessKey", s3.AccessKey},
+			{"storage.s3.secretKey", s3.SecretKey},
+		} {
+			if field.value == "" {
+				problems = append(problems, fmt.Sprintf("%s is required when storage.driver is s3 (env %s)", field.path, envName(field.path)))
+			}
+		}
+	}
+
+	if (cfg.Database.SslCert == "") != (cfg.Database.SslKey == "") {
+		problems = append(problems, "database.sslCert and database.sslKey must be set together")
+	}
+
+	names := map[string]bool{dbresolver.PrimaryName: true}
+	for i := range cfg.Database.Replicas {
+		name := cfg.Database.replicaName(i)
+		if names[name] {
+			problems = append(problems, fmt.Sprintf("database.replicas[%d].name %q is already in use", i, name))
+		}
+		names[name] = true
+	}
+
+	if cfg.Telemetry.Exporter == "otlp" && cfg.Telemetry.Endpoint == "" {
+		problems = append(problems, "telemetry.endpoint is required when telemetry.exporter is otlp")
+	}
+
+	if cfg.AppEnv == constants.AppEnvProduction && len(cfg.JwtSecretKey) < productionJwtSecretMinLength {
+		problems = append(problems, fmt.Sprintf("jwtSecretKey must be at least %d characters in production", productionJwtSecretMinLength))
+	}
+
+	return problems
+}
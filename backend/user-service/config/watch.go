@@ -0,0 +1,74 @@
+package config
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Watch holds a blocking query on the Consul key while the configuration comes
+// from Consul or its snapshot, and re-resolves every layer when the key
+// changes. After a failed query it waits consul.watchInterval seconds before
+// trying again. It returns when ctx is done.
+func Watch(ctx context.Context) {
+	cfg := Get().Consul
+	wait := time.Duration(cfg.WatchInterval) * time.Second
+	source := GetOrigin().Source
+	if (source != SourceConsul && source != SourceSnapshot) || wait <= 0 {
+		return
+	}
+
+	consul, err := consulSettings(cfg)
+	if err != nil {
+		logrus.Errorf("config: cannot watch consul: %v", err)
+		return
+	}
+
+	provider := NewProvider(consul)
+	var index uint64
+	for ctx.Err() == nil {
+		next, err := provider.Wait(ctx, index, wait)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			logrus.Warnf("config: watching consul %s failed, retrying in %s: %v", consul.Key, wait, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+			continue
+		}
+
+		// The first answer only sets the baseline, unless the service booted
+		// from the snapshot and Consul is back. An index that went backwards
+		// means Consul was restored, so everything is read again.
+		if (index == 0 && GetOrigin().Source == SourceSnapshot) || (index != 0 && next != index) {
+			reload()
+		}
+		index = next
+	}
+}
+
+// reload resolves the configuration again and applies it, keeping the running
+// configuration when that fails.
+func reload() {
+	cfg, layers, err := Resolve()
+	if err != nil {
+		logrus.Errorf("config: reload failed, keeping the running configuration: %v", err)
+		return
+	}
+
+	err = Apply(cfg)
+	if err != nil {
+		leases.discard(context.Background())
+		logrus.Errorf("config: rejected reloaded configuration: %v", err)
+		return
+	}
+
+	leases.commit(context.Background())
+	setOrigin(layers)
+}
@@ -0,0 +1,16 @@
+package constants
+
+const (
+	AuditImpersonationStart   = "impersonation.start"
+	AuditImpersonationRequest = "impersonation.request"
+	AuditInvitationCreate     = "invitation.create"
+	AuditInvitationRevoke     = "invitation.revoke"
+	AuditInvitationAccept     = "invitation.accept"
+	AuditRoleUpdate           = "role.update"
+	AuditMembershipUpdate     = "membership.update"
+	AuditMembershipRemove     = "membership.remove"
+	AuditEmailChangeRequest   = "email_change.request"
+	AuditEmailChangeConfirm   = "email_change.confirm"
+	AuditEmailChangeRevert    = "email_change.revert"
+	AuditPasswordChange       = "password.change"
+)
@@ -1,6 +1,12 @@
 package constants
 
 const (
-	UserLogin = "user_login"
-	Token     = "token"
+	UserLogin         = "user_login"
+	Token             = "token"
+	Actor             = "actor"
+	Organization      = "organization"
+	OrganizationClaim = "organization_claim"
+	RequestID         = "request_id"
+	Route             = "route"
+	DatabaseSession   = "database_session"
 )
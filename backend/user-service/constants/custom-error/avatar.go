@@ -0,0 +1,17 @@
+package customerror
+
+import "errors"
+
+var (
+	ErrAvatarRequired     = errors.New("avatar file is required")
+	ErrAvatarTooLarge     = errors.New("avatar file is too large")
+	ErrAvatarInvalidType  = errors.New("avatar must be a JPEG, PNG or WebP image")
+	ErrAvatarInvalidImage = errors.New("avatar image dimensions are too large")
+)
+
+var AvatarErrors = []error{
+	ErrAvatarRequired,
+	ErrAvatarTooLarge,
+	ErrAvatarInvalidType,
+	ErrAvatarInvalidImage,
+}
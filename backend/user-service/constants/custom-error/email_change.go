@@ -0,0 +1,13 @@
+package customerror
+
+import "errors"
+
+var (
+	ErrEmailChangeNotFound = errors.New("email change request not found")
+	ErrEmailChangeExpired  = errors.New("email change request has expired")
+)
+
+var EmailChangeErrors = []error{
+	ErrEmailChangeNotFound,
+	ErrEmailChangeExpired,
+}
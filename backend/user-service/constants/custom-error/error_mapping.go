@@ -2,7 +2,14 @@ package customerror
 
 func ErrMapping(err error) bool {
 	allErrors := make([]error, 0)
-	allErrors = append(GeneralErrors, UserErrors...)
+	allErrors = append(allErrors, GeneralErrors...)
+	allErrors = append(allErrors, UserErrors...)
+	allErrors = append(allErrors, InvitationErrors...)
+	allErrors = append(allErrors, RoleErrors...)
+	allErrors = append(allErrors, OrganizationErrors...)
+	allErrors = append(allErrors, AvatarErrors...)
+	allErrors = append(allErrors, PreferenceErrors...)
+	allErrors = append(allErrors, EmailChangeErrors...)
 
 	for _, item := range allErrors {
 		if err.Error() == item.Error() {
@@ -0,0 +1,17 @@
+package customerror
+
+import "errors"
+
+var (
+	ErrInvitationNotFound = errors.New("invitation not found")
+	ErrInvitationExpired  = errors.New("invitation has expired")
+	ErrInvitationExist    = errors.New("a pending invitation already exists for this email")
+	ErrInvalidStaffRole   = errors.New("role cannot be assigned through an invitation")
+)
+
+var InvitationErrors = []error{
+	ErrInvitationNotFound,
+	ErrInvitationExpired,
+	ErrInvitationExist,
+	ErrInvalidStaffRole,
+}
@@ -0,0 +1,23 @@
+package customerror
+
+import "errors"
+
+var (
+	ErrOrganizationNotFound = errors.New("organization not found")
+	ErrOrganizationRequired = errors.New("organization is required")
+	ErrMembershipNotFound   = errors.New("membership not found")
+	ErrInvalidVenueRole     = errors.New("role cannot be assigned within an organization")
+	ErrNotMember            = errors.New("user is not a member of this organization")
+	ErrOwnerGrantForbidden  = errors.New("only an administrator can grant the owner role")
+	ErrOwnerChangeForbidden = errors.New("only an administrator can change or remove an owner")
+)
+
+var OrganizationErrors = []error{
+	ErrOrganizationNotFound,
+	ErrOrganizationRequired,
+	ErrMembershipNotFound,
+	ErrInvalidVenueRole,
+	ErrNotMember,
+	ErrOwnerGrantForbidden,
+	ErrOwnerChangeForbidden,
+}
@@ -0,0 +1,13 @@
+package customerror
+
+import "errors"
+
+var (
+	ErrPreferenceNotFound = errors.New("preference not found")
+	ErrInvalidPreference  = errors.New("invalid preference payload")
+)
+
+var PreferenceErrors = []error{
+	ErrPreferenceNotFound,
+	ErrInvalidPreference,
+}
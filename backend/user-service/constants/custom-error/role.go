@@ -0,0 +1,17 @@
+package customerror
+
+import "errors"
+
+var (
+	ErrRoleNotFound       = errors.New("role not found")
+	ErrLastAdmin          = errors.New("cannot remove the last administrator")
+	ErrDuplicateRoleUsers = errors.New("a user can only appear once in a bulk role update")
+	ErrTokenRevoked       = errors.New("token has been revoked")
+)
+
+var RoleErrors = []error{
+	ErrRoleNotFound,
+	ErrLastAdmin,
+	ErrDuplicateRoleUsers,
+	ErrTokenRevoked,
+}
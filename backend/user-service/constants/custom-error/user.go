@@ -3,16 +3,39 @@ package customerror
 import "errors"
 
 var (
-	ErrUserNotFound         = errors.New("user not found")
-	ErrPasswordIncorrect    = errors.New("password incorrect")
-	ErrUsernameExist        = errors.New("username already exists")
-	ErrEmailExist           = errors.New("email already exists")
-	ErrPasswordDoesNotMatch = errors.New("password does not match")
+	ErrUserNotFound              = errors.New("user not found")
+	ErrPasswordIncorrect         = errors.New("password incorrect")
+	ErrUsernameExist             = errors.New("username already exists")
+	ErrEmailExist                = errors.New("email already exists")
+	ErrPasswordDoesNotMatch      = errors.New("password does not match")
+	ErrCannotImpersonateSelf     = errors.New("cannot impersonate yourself")
+	ErrCannotImpersonateAdmin    = errors.New("cannot impersonate another administrator")
+	ErrImpersonationNotPermitted = errors.New("operation is not permitted while impersonating")
+	ErrInvalidMergePatch         = errors.New("invalid merge patch payload")
+	ErrFieldNotRemovable         = errors.New("user fields cannot be removed")
+	ErrUnsupportedMediaType      = errors.New("unsupported media type")
+	ErrPreconditionRequired      = errors.New("if-match header is required")
+	ErrVersionMismatch           = errors.New("user has been modified by another request")
+	ErrPasswordTooWeak           = errors.New("password must be 8 to 72 characters long and contain letters and digits")
+	ErrPasswordReused            = errors.New("password has been used recently")
+	ErrPasswordRequired          = errors.New("current password is required to change the email")
 )
 
 var UserErrors = []error{
 	ErrUserNotFound,
 	ErrPasswordIncorrect,
 	ErrUsernameExist,
+	ErrEmailExist,
 	ErrPasswordDoesNotMatch,
+	ErrCannotImpersonateSelf,
+	ErrCannotImpersonateAdmin,
+	ErrImpersonationNotPermitted,
+	ErrInvalidMergePatch,
+	ErrFieldNotRemovable,
+	ErrUnsupportedMediaType,
+	ErrPreconditionRequired,
+	ErrVersionMismatch,
+	ErrPasswordTooWeak,
+	ErrPasswordReused,
+	ErrPasswordRequired,
 }
@@ -0,0 +1,7 @@
+package constants
+
+const (
+	AppEnvDevelopment = "development"
+	AppEnvStaging     = "staging"
+	AppEnvProduction  = "production"
+)
@@ -7,4 +7,10 @@ var (
 	XApiKey       = textproto.CanonicalMIMEHeaderKey("x-api-key")
 	XRequestAt    = textproto.CanonicalMIMEHeaderKey("x-request-at")
 	Authorization = textproto.CanonicalMIMEHeaderKey("authorization")
+	XOrganization = textproto.CanonicalMIMEHeaderKey("x-organization-id")
+	ETag          = textproto.CanonicalMIMEHeaderKey("etag")
+	XRequestID    = textproto.CanonicalMIMEHeaderKey("x-request-id")
+	IfMatch       = textproto.CanonicalMIMEHeaderKey("if-match")
 )
+
+const MIMEMergePatchJSON = "application/merge-patch+json"
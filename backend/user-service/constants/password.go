@@ -0,0 +1,8 @@
+package constants
+
+const (
+	PasswordMinLength = 8
+	// PasswordMaxLength is the longest input bcrypt hashes without truncating.
+	PasswordMaxLength   = 72
+	PasswordHistorySize = 5
+)
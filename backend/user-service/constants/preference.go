@@ -0,0 +1,22 @@
+package constants
+
+const (
+	EventBookingCreated   = "booking_created"
+	EventBookingReminder  = "booking_reminder"
+	EventBookingCancelled = "booking_cancelled"
+	EventPaymentSuccess   = "payment_success"
+	EventPromotion        = "promotion"
+)
+
+var NotificationEvents = []string{
+	EventBookingCreated,
+	EventBookingReminder,
+	EventBookingCancelled,
+	EventPaymentSuccess,
+	EventPromotion,
+}
+
+const (
+	DefaultLanguage = "id"
+	DefaultTimezone = "Asia/Jakarta"
+)
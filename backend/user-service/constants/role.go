@@ -3,4 +3,13 @@ package constants
 const (
 	Admin    = 1
 	Customer = 2
+	Owner    = 3
+	Cashier  = 4
+)
+
+const (
+	AdminCode    = "ADMIN"
+	CustomerCode = "CUSTOMER"
+	OwnerCode    = "OWNER"
+	CashierCode  = "CASHIER"
 )
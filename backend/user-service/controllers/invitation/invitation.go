@@ -0,0 +1,160 @@
+package invitation
+
+import (
+	"net/http"
+	customerror "user-service/common/custom-error"
+	"user-service/common/response"
+	"user-service/domain/dto"
+	"user-service/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type InvitationController struct {
+	service services.IServiceRegistry
+}
+
+type IInvitationController interface {
+	Create(*gin.Context)
+	GetPending(*gin.Context)
+	Revoke(*gin.Context)
+	Accept(*gin.Context)
+}
+
+func NewInvitationController(service services.IServiceRegistry) IInvitationController {
+	return &InvitationController{
+		service: service,
+	}
+}
+
+func (ic *InvitationController) Create(c *gin.Context) {
+	req := &dto.InvitationRequest{}
+	err := c.ShouldBindJSON(req)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	err = validate.Struct(req)
+	if err != nil {
+		errMsg := http.StatusText(http.StatusUnprocessableEntity)
+		errResp := customerror.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Code:    http.StatusUnprocessableEntity,
+			Err:     err,
+			Message: &errMsg,
+			Data:    errResp,
+			Gin:     c,
+		})
+
+		return
+	}
+
+	invitation, err := ic.service.GetInvitation().Create(c.Request.Context(), req)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusCreated,
+		Data: invitation,
+		Gin:  c,
+	})
+}
+
+func (ic *InvitationController) GetPending(c *gin.Context) {
+	invitations, err := ic.service.GetInvitation().GetPending(c.Request.Context())
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Data: invitations,
+		Gin:  c,
+	})
+}
+
+func (ic *InvitationController) Revoke(c *gin.Context) {
+	err := ic.service.GetInvitation().Revoke(c.Request.Context(), c.Param("uuid"))
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Gin:  c,
+	})
+}
+
+func (ic *InvitationController) Accept(c *gin.Context) {
+	req := &dto.AcceptInvitationRequest{}
+	err := c.ShouldBindJSON(req)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	err = validate.Struct(req)
+	if err != nil {
+		errMsg := http.StatusText(http.StatusUnprocessableEntity)
+		errResp := customerror.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Code:    http.StatusUnprocessableEntity,
+			Err:     err,
+			Message: &errMsg,
+			Data:    errResp,
+			Gin:     c,
+		})
+
+		return
+	}
+
+	user, err := ic.service.GetInvitation().Accept(c.Request.Context(), req)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusCreated,
+		Data: user,
+		Gin:  c,
+	})
+}
@@ -0,0 +1,192 @@
+package organization
+
+import (
+	"errors"
+	"net/http"
+	customerror "user-service/common/custom-error"
+	"user-service/common/response"
+	errConstant "user-service/constants/custom-error"
+	"user-service/domain/dto"
+	"user-service/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type OrganizationController struct {
+	service services.IServiceRegistry
+}
+
+type IOrganizationController interface {
+	Create(*gin.Context)
+	GetMine(*gin.Context)
+	GetMembers(*gin.Context)
+	UpsertMember(*gin.Context)
+	RemoveMember(*gin.Context)
+}
+
+func NewOrganizationController(service services.IServiceRegistry) IOrganizationController {
+	return &OrganizationController{
+		service: service,
+	}
+}
+
+func (oc *OrganizationController) Create(c *gin.Context) {
+	req := &dto.OrganizationRequest{}
+	err := c.ShouldBindJSON(req)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	err = validate.Struct(req)
+	if err != nil {
+		errMsg := http.StatusText(http.StatusUnprocessableEntity)
+		errResp := customerror.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Code:    http.StatusUnprocessableEntity,
+			Err:     err,
+			Message: &errMsg,
+			Data:    errResp,
+			Gin:     c,
+		})
+
+		return
+	}
+
+	organization, err := oc.service.GetOrganization().Create(c.Request.Context(), req)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusCreated,
+		Data: organization,
+		Gin:  c,
+	})
+}
+
+func (oc *OrganizationController) GetMine(c *gin.Context) {
+	organizations, err := oc.service.GetOrganization().GetMine(c.Request.Context())
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Data: organizations,
+		Gin:  c,
+	})
+}
+
+func (oc *OrganizationController) GetMembers(c *gin.Context) {
+	members, err := oc.service.GetOrganization().GetMembers(c.Request.Context())
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Data: members,
+		Gin:  c,
+	})
+}
+
+func (oc *OrganizationController) UpsertMember(c *gin.Context) {
+	req := &dto.MembershipRequest{}
+	err := c.ShouldBindJSON(req)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	err = validate.Struct(req)
+	if err != nil {
+		errMsg := http.StatusText(http.StatusUnprocessableEntity)
+		errResp := customerror.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Code:    http.StatusUnprocessableEntity,
+			Err:     err,
+			Message: &errMsg,
+			Data:    errResp,
+			Gin:     c,
+		})
+
+		return
+	}
+
+	member, err := oc.service.GetOrganization().UpsertMember(c.Request.Context(), req)
+	if err != nil {
+		code := http.StatusBadRequest
+		if errors.Is(err, errConstant.ErrOwnerGrantForbidden) || errors.Is(err, errConstant.ErrOwnerChangeForbidden) {
+			code = http.StatusForbidden
+		}
+
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: code,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Data: member,
+		Gin:  c,
+	})
+}
+
+func (oc *OrganizationController) RemoveMember(c *gin.Context) {
+	err := oc.service.GetOrganization().RemoveMember(c.Request.Context(), c.Param("uuid"))
+	if err != nil {
+		code := http.StatusBadRequest
+		if errors.Is(err, errConstant.ErrOwnerChangeForbidden) {
+			code = http.StatusForbidden
+		}
+
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: code,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Gin:  c,
+	})
+}
@@ -0,0 +1,117 @@
+package preference
+
+import (
+	"encoding/json"
+	"net/http"
+	customerror "user-service/common/custom-error"
+	"user-service/common/response"
+	errConstant "user-service/constants/custom-error"
+	"user-service/domain/dto"
+	"user-service/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type PreferenceController struct {
+	service services.IServiceRegistry
+}
+
+type IPreferenceController interface {
+	Get(*gin.Context)
+	Patch(*gin.Context)
+	GetByUserUUID(*gin.Context)
+}
+
+func NewPreferenceController(service services.IServiceRegistry) IPreferenceController {
+	return &PreferenceController{
+		service: service,
+	}
+}
+
+func (pc *PreferenceController) Get(c *gin.Context) {
+	preferences, err := pc.service.GetPreference().Get(c.Request.Context())
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Data: preferences,
+		Gin:  c,
+	})
+}
+
+func (pc *PreferenceController) Patch(c *gin.Context) {
+	req := &dto.PreferencesPatchRequest{}
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(req)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  errConstant.ErrInvalidPreference,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	err = validate.Struct(req)
+	if err != nil {
+		errMsg := http.StatusText(http.StatusUnprocessableEntity)
+		errResp := customerror.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Code:    http.StatusUnprocessableEntity,
+			Err:     err,
+			Message: &errMsg,
+			Data:    errResp,
+			Gin:     c,
+		})
+
+		return
+	}
+
+	preferences, err := pc.service.GetPreference().Patch(c.Request.Context(), req)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Data: preferences,
+		Gin:  c,
+	})
+}
+
+func (pc *PreferenceController) GetByUserUUID(c *gin.Context) {
+	preferences, err := pc.service.GetPreference().GetByUserUUID(c.Request.Context(), c.Param("uuid"))
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Data: preferences,
+		Gin:  c,
+	})
+}
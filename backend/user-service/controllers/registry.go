@@ -1,6 +1,9 @@
 package controllers
 
 import (
+	"user-service/controllers/invitation"
+	"user-service/controllers/organization"
+	"user-service/controllers/preference"
 	"user-service/controllers/user"
 	"user-service/services"
 )
@@ -11,6 +14,9 @@ type Registry struct {
 
 type IControllerRegistry interface {
 	GetUserController() user.IUserController
+	GetInvitationController() invitation.IInvitationController
+	GetOrganizationController() organization.IOrganizationController
+	GetPreferenceController() preference.IPreferenceController
 }
 
 func NewRegistryController(service services.IServiceRegistry) IControllerRegistry {
@@ -22,3 +28,15 @@ func NewRegistryController(service services.IServiceRegistry) IControllerRegistr
 func (r *Registry) GetUserController() user.IUserController {
 	return user.NewUserController(r.service)
 }
+
+func (r *Registry) GetInvitationController() invitation.IInvitationController {
+	return invitation.NewInvitationController(r.service)
+}
+
+func (r *Registry) GetOrganizationController() organization.IOrganizationController {
+	return organization.NewOrganizationController(r.service)
+}
+
+func (r *Registry) GetPreferenceController() preference.IPreferenceController {
+	return preference.NewPreferenceController(r.service)
+}
@@ -1,13 +1,21 @@
 package user
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	customerror "user-service/common/custom-error"
 	"user-service/common/response"
+	"user-service/common/util"
+	"user-service/constants"
+	errConstant "user-service/constants/custom-error"
 	"user-service/domain/dto"
 	"user-service/services"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 )
 
@@ -19,8 +27,17 @@ type IUserController interface {
 	Login(*gin.Context)
 	Register(*gin.Context)
 	Update(*gin.Context)
+	Patch(*gin.Context)
 	GetUserLogin(*gin.Context)
 	GetUserByUUID(*gin.Context)
+	Impersonate(*gin.Context)
+	UpdateRole(*gin.Context)
+	BulkUpdateRole(*gin.Context)
+	UpdateAvatar(*gin.Context)
+	CheckAvailability(*gin.Context)
+	ConfirmEmail(*gin.Context)
+	ChangePassword(*gin.Context)
+	RevertEmail(*gin.Context)
 }
 
 func NewUserController(service services.IServiceRegistry) IUserController {
@@ -30,7 +47,7 @@ func NewUserController(service services.IServiceRegistry) IUserController {
 }
 
 func (uc *UserController) GetUserByUUID(c *gin.Context) {
-	user, err := uc.service.GetUser().GetUserByUUID(c, c.Param("uuid"))
+	user, err := uc.service.GetUser().GetUserByUUID(c.Request.Context(), c.Param("uuid"))
 	if err != nil {
 		response.HttpResponse(response.ParamHTTPResp{
 			Code: http.StatusBadRequest,
@@ -41,6 +58,8 @@ func (uc *UserController) GetUserByUUID(c *gin.Context) {
 		return
 	}
 
+	c.Header(constants.ETag, util.FormatETag(user.Version))
+
 	response.HttpResponse(response.ParamHTTPResp{
 		Code: http.StatusOK,
 		Data: user,
@@ -96,10 +115,15 @@ func (uc *UserController) Login(c *gin.Context) {
 		return
 	}
 
-	res, err := uc.service.GetUser().Login(c, req)
+	res, err := uc.service.GetUser().Login(c.Request.Context(), req)
 	if err != nil {
+		code := http.StatusBadRequest
+		if errors.Is(err, errConstant.ErrNotMember) {
+			code = http.StatusForbidden
+		}
+
 		response.HttpResponse(response.ParamHTTPResp{
-			Code: http.StatusBadRequest,
+			Code: code,
 			Err:  err,
 			Gin:  c,
 		})
@@ -144,7 +168,7 @@ func (uc *UserController) Register(c *gin.Context) {
 		return
 	}
 
-	res, err := uc.service.GetUser().Register(c, req)
+	res, err := uc.service.GetUser().Register(c.Request.Context(), req)
 	if err != nil {
 		response.HttpResponse(response.ParamHTTPResp{
 			Code: http.StatusBadRequest,
@@ -163,6 +187,11 @@ func (uc *UserController) Register(c *gin.Context) {
 }
 
 func (uc *UserController) Update(c *gin.Context) {
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
 	req := &dto.UpdateRequest{}
 	uuid := c.Param("uuid")
 	err := c.ShouldBindJSON(req)
@@ -192,7 +221,159 @@ func (uc *UserController) Update(c *gin.Context) {
 		return
 	}
 
-	user, err := uc.service.GetUser().Update(c, req, uuid)
+	user, err := uc.service.GetUser().Update(c.Request.Context(), req, uuid, version)
+	if err != nil {
+		code := http.StatusBadRequest
+		switch {
+		case errors.Is(err, errConstant.ErrVersionMismatch):
+			code = http.StatusPreconditionFailed
+		case errors.Is(err, errConstant.ErrForbidden):
+			code = http.StatusForbidden
+		}
+
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: code,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	c.Header(constants.ETag, util.FormatETag(user.Version))
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Data: user,
+		Gin:  c,
+	})
+}
+
+func (uc *UserController) Impersonate(c *gin.Context) {
+	req := &dto.ImpersonateRequest{}
+	err := c.ShouldBindJSON(req)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	err = validate.Struct(req)
+	if err != nil {
+		errMsg := http.StatusText(http.StatusUnprocessableEntity)
+		errResp := customerror.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Code:    http.StatusUnprocessableEntity,
+			Err:     err,
+			Message: &errMsg,
+			Data:    errResp,
+			Gin:     c,
+		})
+
+		return
+	}
+
+	res, err := uc.service.GetUser().Impersonate(c.Request.Context(), req, c.Param("uuid"))
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code:  http.StatusOK,
+		Data:  res,
+		Token: &res.Token,
+		Gin:   c,
+	})
+}
+
+func (uc *UserController) UpdateRole(c *gin.Context) {
+	req := &dto.UpdateRoleRequest{}
+	err := c.ShouldBindJSON(req)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	err = validate.Struct(req)
+	if err != nil {
+		errMsg := http.StatusText(http.StatusUnprocessableEntity)
+		errResp := customerror.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Code:    http.StatusUnprocessableEntity,
+			Err:     err,
+			Message: &errMsg,
+			Data:    errResp,
+			Gin:     c,
+		})
+
+		return
+	}
+
+	user, err := uc.service.GetUser().UpdateRole(c.Request.Context(), req, c.Param("uuid"))
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Data: user,
+		Gin:  c,
+	})
+}
+
+func (uc *UserController) BulkUpdateRole(c *gin.Context) {
+	req := &dto.BulkUpdateRoleRequest{}
+	err := c.ShouldBindJSON(req)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	err = validate.Struct(req)
+	if err != nil {
+		errMsg := http.StatusText(http.StatusUnprocessableEntity)
+		errResp := customerror.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Code:    http.StatusUnprocessableEntity,
+			Err:     err,
+			Message: &errMsg,
+			Data:    errResp,
+			Gin:     c,
+		})
+
+		return
+	}
+
+	users, err := uc.service.GetUser().BulkUpdateRole(c.Request.Context(), req)
 	if err != nil {
 		response.HttpResponse(response.ParamHTTPResp{
 			Code: http.StatusBadRequest,
@@ -203,9 +384,390 @@ func (uc *UserController) Update(c *gin.Context) {
 		return
 	}
 
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Data: users,
+		Gin:  c,
+	})
+}
+
+func (uc *UserController) UpdateAvatar(c *gin.Context) {
+	file, err := c.FormFile("avatar")
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  errConstant.ErrAvatarRequired,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	user, err := uc.service.GetUser().UpdateAvatar(c.Request.Context(), file)
+	if err != nil {
+		code := http.StatusBadRequest
+		switch {
+		case errors.Is(err, errConstant.ErrAvatarTooLarge):
+			code = http.StatusRequestEntityTooLarge
+		case errors.Is(err, errConstant.ErrAvatarInvalidType):
+			code = http.StatusUnsupportedMediaType
+		}
+
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: code,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
 	response.HttpResponse(response.ParamHTTPResp{
 		Code: http.StatusOK,
 		Data: user,
 		Gin:  c,
 	})
 }
+
+func (uc *UserController) CheckAvailability(c *gin.Context) {
+	req := &dto.AvailabilityRequest{}
+	err := c.ShouldBindQuery(req)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	err = validate.Struct(req)
+	if err != nil {
+		errMsg := http.StatusText(http.StatusUnprocessableEntity)
+		errResp := customerror.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Code:    http.StatusUnprocessableEntity,
+			Err:     err,
+			Message: &errMsg,
+			Data:    errResp,
+			Gin:     c,
+		})
+
+		return
+	}
+
+	availability, err := uc.service.GetUser().CheckAvailability(c.Request.Context(), req)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Data: availability,
+		Gin:  c,
+	})
+}
+
+func (uc *UserController) Patch(c *gin.Context) {
+	contentType := c.ContentType()
+	if contentType != constants.MIMEMergePatchJSON && contentType != binding.MIMEJSON {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusUnsupportedMediaType,
+			Err:  errConstant.ErrUnsupportedMediaType,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	req, err := decodeMergePatch(body)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	err = validate.Struct(req)
+	if err != nil {
+		errMsg := http.StatusText(http.StatusUnprocessableEntity)
+		errResp := customerror.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Code:    http.StatusUnprocessableEntity,
+			Err:     err,
+			Message: &errMsg,
+			Data:    errResp,
+			Gin:     c,
+		})
+
+		return
+	}
+
+	user, err := uc.service.GetUser().Patch(c.Request.Context(), req, c.Param("uuid"), version)
+	if err != nil {
+		code := http.StatusBadRequest
+		switch {
+		case errors.Is(err, errConstant.ErrVersionMismatch):
+			code = http.StatusPreconditionFailed
+		case errors.Is(err, errConstant.ErrForbidden):
+			code = http.StatusForbidden
+		}
+
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: code,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	c.Header(constants.ETag, util.FormatETag(user.Version))
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Data: user,
+		Gin:  c,
+	})
+}
+
+func (uc *UserController) ConfirmEmail(c *gin.Context) {
+	req := &dto.EmailChangeTokenRequest{}
+	err := c.ShouldBindJSON(req)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	err = validate.Struct(req)
+	if err != nil {
+		errMsg := http.StatusText(http.StatusUnprocessableEntity)
+		errResp := customerror.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Code:    http.StatusUnprocessableEntity,
+			Err:     err,
+			Message: &errMsg,
+			Data:    errResp,
+			Gin:     c,
+		})
+
+		return
+	}
+
+	user, err := uc.service.GetUser().ConfirmEmail(c.Request.Context(), req)
+	if err != nil {
+		code := http.StatusBadRequest
+		if errors.Is(err, errConstant.ErrEmailChangeExpired) {
+			code = http.StatusGone
+		}
+
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: code,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Data: user,
+		Gin:  c,
+	})
+}
+
+func (uc *UserController) RevertEmail(c *gin.Context) {
+	req := &dto.EmailChangeTokenRequest{}
+	err := c.ShouldBindJSON(req)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	err = validate.Struct(req)
+	if err != nil {
+		errMsg := http.StatusText(http.StatusUnprocessableEntity)
+		errResp := customerror.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Code:    http.StatusUnprocessableEntity,
+			Err:     err,
+			Message: &errMsg,
+			Data:    errResp,
+			Gin:     c,
+		})
+
+		return
+	}
+
+	user, err := uc.service.GetUser().RevertEmail(c.Request.Context(), req)
+	if err != nil {
+		code := http.StatusBadRequest
+		if errors.Is(err, errConstant.ErrEmailChangeExpired) {
+			code = http.StatusGone
+		}
+
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: code,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Data: user,
+		Gin:  c,
+	})
+}
+
+func (uc *UserController) ChangePassword(c *gin.Context) {
+	req := &dto.ChangePasswordRequest{}
+	err := c.ShouldBindJSON(req)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	err = validate.Struct(req)
+	if err != nil {
+		errMsg := http.StatusText(http.StatusUnprocessableEntity)
+		errResp := customerror.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Code:    http.StatusUnprocessableEntity,
+			Err:     err,
+			Message: &errMsg,
+			Data:    errResp,
+			Gin:     c,
+		})
+
+		return
+	}
+
+	res, err := uc.service.GetUser().ChangePassword(c.Request.Context(), req)
+	if err != nil {
+		code := http.StatusBadRequest
+		switch {
+		case errors.Is(err, errConstant.ErrPasswordTooWeak), errors.Is(err, errConstant.ErrPasswordReused):
+			code = http.StatusUnprocessableEntity
+		}
+
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: code,
+			Err:  err,
+			Gin:  c,
+		})
+
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code:  http.StatusOK,
+		Data:  res.User,
+		Token: &res.Token,
+		Gin:   c,
+	})
+}
+
+// ifMatch returns the version the client last read. It writes the error
+// response itself when the header is missing or cannot match.
+func ifMatch(c *gin.Context) (uint, bool) {
+	header := c.GetHeader(constants.IfMatch)
+	if header == "" {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusPreconditionRequired,
+			Err:  errConstant.ErrPreconditionRequired,
+			Gin:  c,
+		})
+
+		return 0, false
+	}
+
+	version, ok := util.ParseETag(header)
+	if !ok {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusPreconditionFailed,
+			Err:  errConstant.ErrVersionMismatch,
+			Gin:  c,
+		})
+
+		return 0, false
+	}
+
+	return version, true
+}
+
+// decodeMergePatch reads an RFC 7396 document. Every user column is
+// mandatory, so a null member (a removal in merge patch terms) is rejected,
+// and unknown members such as password fail the decode.
+func decodeMergePatch(body []byte) (*dto.PatchUserRequest, error) {
+	members := make(map[string]json.RawMessage)
+	err := json.Unmarshal(body, &members)
+	if err != nil {
+		return nil, errConstant.ErrInvalidMergePatch
+	}
+
+	for _, value := range members {
+		if bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+			return nil, errConstant.ErrFieldNotRemovable
+		}
+	}
+
+	req := &dto.PatchUserRequest{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(req)
+	if err != nil {
+		return nil, errConstant.ErrInvalidMergePatch
+	}
+
+	return req, nil
+}
@@ -0,0 +1,286 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"user-service/common/logger"
+
+	"gorm.io/gorm"
+)
+
+const table = "schema_migrations"
+
+// lockKey identifies the advisory lock held while migrating so that pods
+// starting together apply each version exactly once.
+const lockKey int64 = 0x75736572736376
+
+// noTransaction marks a migration that must run outside a transaction, such as
+// CREATE INDEX CONCURRENTLY. Such migrations must be safe to run again.
+const noTransaction = "-- migrate:no-transaction"
+
+//go:embed sql/*.sql
+var files embed.FS
+
+var filename = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Version   uint
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+func load() ([]migration, error) {
+	entries, err := fs.ReadDir(files, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[uint]*migration{}
+	for _, entry := range entries {
+		match := filename.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file %s", entry.Name())
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := files.ReadFile("sql/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[uint(version)]
+		if !ok {
+			m = &migration{Version: uint(version), Name: match[2]}
+			byVersion[uint(version)] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %s and %s", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	all := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s needs both an up and a down file", m.Version, m.Name)
+		}
+		all = append(all, *m)
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i].Version < all[j].Version })
+	return all, nil
+}
+
+// Up applies every pending migration in version order.
+func Up(ctx context.Context, db *gorm.DB) error {
+	all, err := load()
+	if err != nil {
+		return err
+	}
+
+	return withLock(ctx, db, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range all {
+			if _, ok := applied[m.Version]; ok {
+				continue
+			}
+
+			logger.FromContext(ctx).Infof("applying migration %04d_%s", m.Version, m.Name)
+			err = run(ctx, conn, m.Up,
+				fmt.Sprintf(`INSERT INTO %s (version, name) VALUES (%d, '%s')`, table, m.Version, m.Name))
+			if err != nil {
+				return fmt.Errorf("migration %04d_%s: %w", m.Version, m.Name, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Down reverts the latest steps applied migrations.
+func Down(ctx context.Context, db *gorm.DB, steps int) error {
+	all, err := load()
+	if err != nil {
+		return err
+	}
+
+	return withLock(ctx, db, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(all) - 1; i >= 0 && steps > 0; i-- {
+			m := all[i]
+			if _, ok := applied[m.Version]; !ok {
+				continue
+			}
+
+			logger.FromContext(ctx).Infof("reverting migration %04d_%s", m.Version, m.Name)
+			err = run(ctx, conn, m.Down,
+				fmt.Sprintf(`DELETE FROM %s WHERE version = %d`, table, m.Version))
+			if err != nil {
+				return fmt.Errorf("migration %04d_%s: %w", m.Version, m.Name, err)
+			}
+			steps--
+		}
+
+		return nil
+	})
+}
+
+// GetStatus lists every known migration and whether it has been applied.
+func GetStatus(ctx context.Context, db *gorm.DB) ([]Status, error) {
+	all, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	applied := map[uint]time.Time{}
+	if db.WithContext(ctx).Migrator().HasTable(table) {
+		var rows []struct {
+			Version   uint
+			AppliedAt time.Time
+		}
+		err = db.WithContext(ctx).Raw(`SELECT version, applied_at FROM ` + table).Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			applied[row.Version] = row.AppliedAt
+		}
+	}
+
+	statuses := make([]Status, 0, len(all))
+	for _, m := range all {
+		status := Status{Version: m.Version, Name: m.Name}
+		if at, ok := applied[m.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = &at
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// withLock runs fn on a dedicated connection holding the migration advisory
+// lock. Session level locks are tied to the connection, which is why the pool
+// cannot be used directly.
+func withLock(ctx context.Context, db *gorm.DB, fn func(*sql.Conn) error) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockKey)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, lockKey)
+		if err != nil {
+			logger.FromContext(ctx).Errorf("failed to release migration lock: %v", err)
+		}
+	}()
+
+	_, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+table+` (
+		version bigint PRIMARY KEY,
+		name varchar(255) NOT NULL,
+		applied_at timestamptz NOT NULL DEFAULT now()
+	)`)
+	if err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[uint]struct{}, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version FROM `+table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[uint]struct{}{}
+	for rows.Next() {
+		var version uint
+		err = rows.Scan(&version)
+		if err != nil {
+			return nil, err
+		}
+		applied[version] = struct{}{}
+	}
+
+	return applied, rows.Err()
+}
+
+// run executes script and then record, which updates schema_migrations, in a
+// single transaction unless the script opts out of it.
+func run(ctx context.Context, conn *sql.Conn, script, record string) error {
+	if strings.HasPrefix(strings.TrimSpace(script), noTransaction) {
+		_, err := conn.ExecContext(ctx, script)
+		if err != nil {
+			return err
+		}
+
+		_, err = conn.ExecContext(ctx, record)
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, script)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, record)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
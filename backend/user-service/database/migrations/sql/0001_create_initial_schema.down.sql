@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS "password_histories";
+DROP TABLE IF EXISTS "email_changes";
+DROP TABLE IF EXISTS "user_preferences";
+DROP TABLE IF EXISTS "memberships";
+DROP TABLE IF EXISTS "organizations";
+DROP TABLE IF EXISTS "invitations";
+DROP TABLE IF EXISTS "audit_logs";
+DROP TABLE IF EXISTS "users";
+DROP TABLE IF EXISTS "roles";
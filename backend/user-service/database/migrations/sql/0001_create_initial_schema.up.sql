@@ -0,0 +1,129 @@
+-- Baseline matching the schema GORM AutoMigrate produced. Every statement is
+-- guarded so deployments that were migrated by AutoMigrate adopt it as is.
+
+CREATE TABLE IF NOT EXISTS "roles" (
+    "id" bigserial,
+    "code" text NOT NULL,
+    "name" text NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "users" (
+    "id" bigserial,
+    "uuid" uuid NOT NULL,
+    "name" varchar(100) NOT NULL,
+    "username" varchar(20) NOT NULL,
+    "password" varchar(255) NOT NULL,
+    "phone_number" varchar(15) NOT NULL,
+    "email" varchar(100) NOT NULL,
+    "role_id" bigint NOT NULL,
+    "token_version" bigint NOT NULL DEFAULT 0,
+    "avatar_key" varchar(255),
+    "version" bigint NOT NULL DEFAULT 1,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_users_role" FOREIGN KEY ("role_id") REFERENCES "roles"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS "audit_logs" (
+    "id" bigserial,
+    "uuid" uuid NOT NULL,
+    "actor_uuid" uuid NOT NULL,
+    "subject_uuid" uuid,
+    "action" varchar(50) NOT NULL,
+    "method" varchar(10),
+    "path" varchar(255),
+    "status_code" bigint,
+    "ip_address" varchar(45),
+    "description" varchar(255),
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_actor_uuid" ON "audit_logs" ("actor_uuid");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_subject_uuid" ON "audit_logs" ("subject_uuid");
+
+CREATE TABLE IF NOT EXISTS "invitations" (
+    "id" bigserial,
+    "uuid" uuid NOT NULL,
+    "email" varchar(100) NOT NULL,
+    "role_id" bigint NOT NULL,
+    "token_hash" varchar(64) NOT NULL,
+    "invited_by" uuid NOT NULL,
+    "expired_at" timestamptz NOT NULL,
+    "accepted_at" timestamptz,
+    "revoked_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_invitations_role" FOREIGN KEY ("role_id") REFERENCES "roles"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_invitations_token_hash" ON "invitations" ("token_hash");
+
+CREATE TABLE IF NOT EXISTS "organizations" (
+    "id" bigserial,
+    "uuid" uuid NOT NULL,
+    "name" varchar(100) NOT NULL,
+    "address" varchar(255),
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "memberships" (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "organization_id" bigint NOT NULL,
+    "role_id" bigint NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_memberships_role" FOREIGN KEY ("role_id") REFERENCES "roles"("id") ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT "fk_memberships_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT "fk_memberships_organization" FOREIGN KEY ("organization_id") REFERENCES "organizations"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_memberships_user_organization" ON "memberships" ("user_id", "organization_id");
+
+CREATE TABLE IF NOT EXISTS "user_preferences" (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "preferences" jsonb NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_user_preferences_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_user_preferences_user_id" ON "user_preferences" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "email_changes" (
+    "id" bigserial,
+    "uuid" uuid NOT NULL,
+    "user_id" bigint NOT NULL,
+    "previous_email" varchar(100) NOT NULL,
+    "new_email" varchar(100) NOT NULL,
+    "confirm_token_hash" varchar(64) NOT NULL,
+    "revert_token_hash" varchar(64) NOT NULL,
+    "expired_at" timestamptz NOT NULL,
+    "revert_expired_at" timestamptz NOT NULL,
+    "confirmed_at" timestamptz,
+    "reverted_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_email_changes_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_email_changes_user_id" ON "email_changes" ("user_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_email_changes_confirm_token_hash" ON "email_changes" ("confirm_token_hash");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_email_changes_revert_token_hash" ON "email_changes" ("revert_token_hash");
+
+CREATE TABLE IF NOT EXISTS "password_histories" (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "password" varchar(255) NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_password_histories_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_password_histories_user_id" ON "password_histories" ("user_id");
@@ -0,0 +1,128 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"user-service/domain/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// typeAliases maps the types GORM declares to the udt names postgres reports.
+var typeAliases = map[string]string{
+	"bigserial":   "int8",
+	"bigint":      "int8",
+	"serial":      "int4",
+	"integer":     "int4",
+	"smallserial": "int2",
+	"smallint":    "int2",
+	"boolean":     "bool",
+	"real":        "float4",
+	"double":      "float8",
+	"decimal":     "numeric",
+	"varchar":     "varchar",
+}
+
+var declaredType = regexp.MustCompile(`^(\w+)(?: precision)?(?:\((\d+)\))?`)
+
+// Verify compares the migrated schema with the GORM models and returns one
+// line per difference. An empty result means the SQL migrations and the
+// models agree.
+func Verify(ctx context.Context, db *gorm.DB) ([]string, error) {
+	db = db.WithContext(ctx)
+	migrator := db.Migrator()
+
+	var issues []string
+	for _, model := range models.All() {
+		stmt := &gorm.Statement{DB: db}
+		err := stmt.Parse(model)
+		if err != nil {
+			return nil, err
+		}
+		table := stmt.Schema.Table
+
+		if !migrator.HasTable(model) {
+			issues = append(issues, fmt.Sprintf("table %s is missing", table))
+			continue
+		}
+
+		columnTypes, err := migrator.ColumnTypes(model)
+		if err != nil {
+			return nil, err
+		}
+
+		columns := map[string]gorm.ColumnType{}
+		for _, columnType := range columnTypes {
+			columns[columnType.Name()] = columnType
+		}
+
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" || field.IgnoreMigration {
+				continue
+			}
+
+			column, ok := columns[field.DBName]
+			if !ok {
+				issues = append(issues, fmt.Sprintf("column %s.%s is missing", table, field.DBName))
+				continue
+			}
+			delete(columns, field.DBName)
+
+			issues = append(issues, compareColumn(db, table, field, column)...)
+		}
+
+		for name := range columns {
+			issues = append(issues, fmt.Sprintf("column %s.%s has no model field", table, name))
+		}
+
+		for _, index := range stmt.Schema.ParseIndexes() {
+			if !migrator.HasIndex(model, index.Name) {
+				issues = append(issues, fmt.Sprintf("index %s on %s is missing", index.Name, table))
+			}
+		}
+
+		for _, relation := range stmt.Schema.Relationships.Relations {
+			constraint := relation.ParseConstraint()
+			if constraint == nil || constraint.Schema != stmt.Schema {
+				continue
+			}
+
+			if !migrator.HasConstraint(model, constraint.Name) {
+				issues = append(issues, fmt.Sprintf("constraint %s on %s is missing", constraint.Name, table))
+			}
+		}
+	}
+
+	return issues, nil
+}
+
+func compareColumn(db *gorm.DB, table string, field *schema.Field, column gorm.ColumnType) []string {
+	var issues []string
+
+	match := declaredType.FindStringSubmatch(db.Dialector.DataTypeOf(field))
+	if match != nil {
+		want := match[1]
+		if alias, ok := typeAliases[want]; ok {
+			want = alias
+		}
+
+		if got := column.DatabaseTypeName(); got != want {
+			issues = append(issues, fmt.Sprintf("column %s.%s is %s, model declares %s", table, field.DBName, got, want))
+		} else if match[2] != "" {
+			size, _ := strconv.ParseInt(match[2], 10, 64)
+			if length, ok := column.Length(); ok && length != size {
+				issues = append(issues, fmt.Sprintf("column %s.%s has length %d, model declares %d", table, field.DBName, length, size))
+			}
+		}
+	}
+
+	notNull := field.NotNull || field.PrimaryKey
+	if nullable, ok := column.Nullable(); ok && nullable == notNull {
+		issues = append(issues, fmt.Sprintf("column %s.%s nullability differs from the model", table, field.DBName))
+	}
+
+	return issues
+}
@@ -0,0 +1,72 @@
+package seeders
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed fixtures
+var fixtures embed.FS
+
+const defaultFixture = "default"
+
+type Fixture struct {
+	Roles []RoleFixture `yaml:"roles"`
+	Users []UserFixture `yaml:"users"`
+}
+
+type RoleFixture struct {
+	Code string `yaml:"code"`
+	Name string `yaml:"name"`
+}
+
+// UserFixture never carries a password. It is read from the PasswordEnv
+// environment variable or generated when the user is created.
+type UserFixture struct {
+	Username    string `yaml:"username"`
+	Name        string `yaml:"name"`
+	Email       string `yaml:"email"`
+	PhoneNumber string `yaml:"phoneNumber"`
+	Role        string `yaml:"role"`
+	PasswordEnv string `yaml:"passwordEnv"`
+}
+
+// LoadFixture reads fixtures/<appEnv>.yaml, .yml or .json and falls back to
+// the default fixture. It returns the name of the file it used.
+func LoadFixture(appEnv string) (*Fixture, string, error) {
+	for _, name := range []string{appEnv, defaultFixture} {
+		if name == "" {
+			continue
+		}
+
+		for _, ext := range []string{".yaml", ".yml", ".json"} {
+			path := "fixtures/" + name + ext
+			content, err := fixtures.ReadFile(path)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, "", err
+			}
+
+			// JSON is valid YAML, so one decoder handles both formats.
+			decoder := yaml.NewDecoder(bytes.NewReader(content))
+			decoder.KnownFields(true)
+
+			var fixture Fixture
+			err = decoder.Decode(&fixture)
+			if err != nil {
+				return nil, "", fmt.Errorf("%s: %w", path, err)
+			}
+
+			return &fixture, path, nil
+		}
+	}
+
+	return nil, "", fmt.Errorf("no fixture found for app env %q", appEnv)
+}
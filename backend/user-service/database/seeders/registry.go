@@ -1,22 +1,73 @@
 package seeders
 
-import "gorm.io/gorm"
+import (
+	"context"
+	"fmt"
+	"user-service/common/logger"
+
+	"gorm.io/gorm"
+)
+
+const (
+	SeederRoles = "roles"
+	SeederUsers = "users"
+)
+
+// Options selects a single seeder with Only and reports the planned changes
+// without writing them with DryRun.
+type Options struct {
+	Only   string
+	DryRun bool
+}
 
 type Registry struct {
-	db *gorm.DB
+	db     *gorm.DB
+	appEnv string
 }
 
 type ISeederRegistry interface {
-	Run()
+	Run(context.Context, Options) error
 }
 
-func NewSeederRegistry(db *gorm.DB) ISeederRegistry {
+func NewSeederRegistry(db *gorm.DB, appEnv string) ISeederRegistry {
 	return &Registry{
-		db: db,
+		db:     db,
+		appEnv: appEnv,
+	}
+}
+
+func (r *Registry) Run(ctx context.Context, opts Options) error {
+	if opts.Only != "" && opts.Only != SeederRoles && opts.Only != SeederUsers {
+		return fmt.Errorf("unknown seeder %q, expected %s or %s", opts.Only, SeederRoles, SeederUsers)
+	}
+
+	fixture, path, err := LoadFixture(r.appEnv)
+	if err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Infof("%sseeding from %s", prefix(opts.DryRun), path)
+
+	if opts.Only == "" || opts.Only == SeederRoles {
+		err = seedRoles(ctx, r.db, fixture.Roles, opts.DryRun)
+		if err != nil {
+			return fmt.Errorf("seed roles: %w", err)
+		}
+	}
+
+	if opts.Only == "" || opts.Only == SeederUsers {
+		err = seedUsers(ctx, r.db, fixture.Users, r.appEnv, opts.DryRun)
+		if err != nil {
+			return fmt.Errorf("seed users: %w", err)
+		}
 	}
+
+	return nil
 }
 
-func (r *Registry) Run() {
-	RunRoleSeeder(r.db)
-	RunUserSeeder(r.db)
+func prefix(dryRun bool) string {
+	if dryRun {
+		return "[dry-run] "
+	}
+
+	return ""
 }
@@ -1,33 +1,44 @@
 package seeders
 
 import (
+	"context"
+	"user-service/common/logger"
 	"user-service/domain/models"
 
-	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
-func RunRoleSeeder(db *gorm.DB) {
-	roles := []models.Role{
-		{
-			Code: "ADMIN",
-			Name: "Administrator",
-		},
-		{
-			Code: "CUSTOMER",
-			Name: "Customer",
-		},
-	}
-
-	logrus.Info("Seeder role start")
-	for _, role := range roles {
-		err := db.FirstOrCreate(&role, models.Role{Code: role.Code}).Error
+func seedRoles(ctx context.Context, db *gorm.DB, fixtures []RoleFixture, dryRun bool) error {
+	log := logger.FromContext(ctx)
+	for _, fixture := range fixtures {
+		var role models.Role
+		err := db.WithContext(ctx).Where("code = ?", fixture.Code).Limit(1).Find(&role).Error
 		if err != nil {
-			logrus.Errorf("failed to seed role: %v", err)
-			panic(err)
+			return err
 		}
 
-		logrus.Infof("role %s successfully seeded", role.Code)
+		switch {
+		case role.ID == 0:
+			log.Infof("%srole %s: create", prefix(dryRun), fixture.Code)
+			if dryRun {
+				continue
+			}
+
+			err = db.WithContext(ctx).Create(&models.Role{Code: fixture.Code, Name: fixture.Name}).Error
+		case role.Name != fixture.Name:
+			log.Infof("%srole %s: update name %q to %q", prefix(dryRun), fixture.Code, role.Name, fixture.Name)
+			if dryRun {
+				continue
+			}
+
+			err = db.WithContext(ctx).Model(&role).Update("name", fixture.Name).Error
+		default:
+			log.Infof("%srole %s: unchanged", prefix(dryRun), fixture.Code)
+		}
+		if err != nil {
+			return err
+		}
 	}
-	logrus.Info("Seeder role finish")
+
+	return nil
 }
@@ -1,42 +1,206 @@
 package seeders
 
 import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"user-service/common/hashing"
+	"user-service/common/logger"
+	"user-service/common/util"
 	"user-service/constants"
 	"user-service/domain/models"
 
 	"github.com/google/uuid"
-	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
-func RunUserSeeder(db *gorm.DB) {
-	hashPass, err := bcrypt.GenerateFromPassword([]byte("admin123"), bcrypt.DefaultCost)
+// defaultPasswords are refused in production, both for new users and for
+// users seeded by earlier releases.
+var defaultPasswords = []string{"admin123", "admin", "password", "12345678"}
+
+// legacyAdmins are the accounts earlier releases seeded with a default
+// password. Only they and the fixture users are checked, since every check
+// costs a bcrypt comparison per default password.
+var legacyAdmins = []string{"admin"}
+
+func seedUsers(ctx context.Context, db *gorm.DB, fixtures []UserFixture, appEnv string, dryRun bool) error {
+	log := logger.FromContext(ctx)
+	production := appEnv == constants.AppEnvProduction
+
+	if production {
+		err := refuseDefaultAdmins(ctx, db, fixtures)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, fixture := range fixtures {
+		var role models.Role
+		err := db.WithContext(ctx).Where("code = ?", fixture.Role).Limit(1).Find(&role).Error
+		if err != nil {
+			return err
+		}
+		if role.ID == 0 && !dryRun {
+			return fmt.Errorf("user %s: role %s does not exist", fixture.Username, fixture.Role)
+		}
+
+		var user models.User
+		err = db.WithContext(ctx).Where("username = ?", fixture.Username).Limit(1).Find(&user).Error
+		if err != nil {
+			return err
+		}
+
+		if user.ID == 0 {
+			password, generated, err := fixturePassword(fixture, production)
+			if err != nil {
+				return err
+			}
+
+			log.Infof("%suser %s: create", prefix(dryRun), fixture.Username)
+			if dryRun {
+				continue
+			}
+
+			hashedPass, err := hashing.Generate(ctx, []byte(password), bcrypt.DefaultCost)
+			if err != nil {
+				return err
+			}
+
+			err = db.WithContext(ctx).Create(&models.User{
+				UUID:        uuid.New(),
+				Name:        fixture.Name,
+				Username:    fixture.Username,
+				Password:    string(hashedPass),
+				PhoneNumber: fixture.PhoneNumber,
+				Email:       fixture.Email,
+				RoleID:      role.ID,
+			}).Error
+			if err != nil {
+				return err
+			}
+
+			if generated {
+				fmt.Printf("generated password for %s: %s\n", fixture.Username, password)
+			}
+			continue
+		}
+
+		if production && hasDefaultPassword(ctx, &user) {
+			return fmt.Errorf("user %s still uses a default password, rotate it before seeding production", user.Username)
+		}
+
+		changes := map[string]any{}
+		if user.Name != fixture.Name {
+			changes["name"] = fixture.Name
+		}
+		if user.Email != fixture.Email {
+			changes["email"] = fixture.Email
+		}
+		if user.PhoneNumber != fixture.PhoneNumber {
+			changes["phone_number"] = fixture.PhoneNumber
+		}
+		if role.ID != 0 && user.RoleID != role.ID {
+			changes["role_id"] = role.ID
+		}
+
+		if len(changes) == 0 {
+			log.Infof("%suser %s: unchanged", prefix(dryRun), fixture.Username)
+			continue
+		}
+
+		log.Infof("%suser %s: update %v", prefix(dryRun), fixture.Username, slices.Sorted(maps.Keys(changes)))
+		if dryRun {
+			continue
+		}
+
+		changes["version"] = gorm.Expr("version + 1")
+		err = db.WithContext(ctx).Model(&user).Updates(changes).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fixturePassword reads the password from the fixture's environment variable,
+// or generates one outside production. Existing passwords are never
+// overwritten, so it is only used when a user is created.
+func fixturePassword(fixture UserFixture, production bool) (string, bool, error) {
+	password := ""
+	if fixture.PasswordEnv != "" {
+		password = os.Getenv(fixture.PasswordEnv)
+	}
+
+	if password == "" {
+		if production {
+			return "", false, fmt.Errorf("user %s: set %s, production does not generate passwords", fixture.Username, fixture.PasswordEnv)
+		}
+
+		generated, err := util.GeneratePassword()
+		return generated, true, err
+	}
+
+	err := util.CheckPasswordPolicy(password)
 	if err != nil {
-		panic(err)
+		return "", false, fmt.Errorf("user %s: %s: %w", fixture.Username, fixture.PasswordEnv, err)
 	}
 
-	users := []models.User{
-		{
-			UUID:        uuid.New(),
-			Name:        "Administrator",
-			Username:    "admin",
-			Password:    string(hashPass),
-			PhoneNumber: "081285942567",
-			Email:       "admin@gmail.com",
-			RoleID:      constants.Admin,
-		},
+	if production && isDefaultPassword(password) {
+		return "", false, fmt.Errorf("user %s: %s is a default password", fixture.Username, fixture.PasswordEnv)
 	}
 
-	logrus.Info("Seeder user start")
-	for _, user := range users {
-		err := db.FirstOrCreate(&user, models.User{Username: user.Username}).Error
-		if err != nil {
-			logrus.Errorf("failed to seed user: %v", err)
-			panic(err)
+	return password, false, nil
+}
+
+func isDefaultPassword(password string) bool {
+	for _, candidate := range defaultPasswords {
+		if password == candidate {
+			return true
 		}
+	}
+
+	return false
+}
+
+// refuseDefaultAdmins fails when an admin seeded by earlier releases still has
+// a default password. Fixture users are checked while they are seeded.
+func refuseDefaultAdmins(ctx context.Context, db *gorm.DB, fixtures []UserFixture) error {
+	usernames := slices.DeleteFunc(slices.Clone(legacyAdmins), func(username string) bool {
+		return slices.ContainsFunc(fixtures, func(fixture UserFixture) bool {
+			return fixture.Username == username
+		})
+	})
+	if len(usernames) == 0 {
+		return nil
+	}
 
-		logrus.Infof("user %d successfully seeded", user.ID)
+	var admins []models.User
+	err := db.WithContext(ctx).
+		Where("role_id = ? AND username IN ?", constants.Admin, usernames).
+		Find(&admins).Error
+	if err != nil {
+		return err
 	}
-	logrus.Info("Seeder user finish")
+
+	for _, admin := range admins {
+		if hasDefaultPassword(ctx, &admin) {
+			return fmt.Errorf("admin %s still uses a default password, rotate it before seeding production", admin.Username)
+		}
+	}
+
+	return nil
+}
+
+func hasDefaultPassword(ctx context.Context, user *models.User) bool {
+	for _, candidate := range defaultPasswords {
+		if hashing.Compare(ctx, []byte(user.Password), []byte(candidate)) == nil {
+			return true
+		}
+	}
+
+	return false
 }
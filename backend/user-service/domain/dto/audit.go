@@ -0,0 +1,14 @@
+package dto
+
+import "github.com/google/uuid"
+
+type AuditRequest struct {
+	ActorUUID   uuid.UUID
+	SubjectUUID *uuid.UUID
+	Action      string
+	Method      string
+	Path        string
+	StatusCode  int
+	IPAddress   string
+	Description string
+}
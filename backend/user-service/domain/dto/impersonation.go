@@ -0,0 +1,24 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Actor struct {
+	UUID     uuid.UUID `json:"uuid"`
+	Username string    `json:"username"`
+	Role     string    `json:"role"`
+}
+
+type ImpersonateRequest struct {
+	Reason string `json:"reason" validate:"required,max=255"`
+}
+
+type ImpersonateResponse struct {
+	User      UserResponse `json:"user"`
+	Actor     Actor        `json:"actor"`
+	ExpiresAt time.Time    `json:"expiresAt"`
+	Token     string       `json:"-"`
+}
@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type InvitationRequest struct {
+	Email  string `json:"email" validate:"required,email"`
+	RoleID uint   `json:"roleId" validate:"required"`
+}
+
+type AcceptInvitationRequest struct {
+	Token           string `json:"token" validate:"required"`
+	Name            string `json:"name" validate:"required"`
+	Username        string `json:"username" validate:"required"`
+	Password        string `json:"password" validate:"required"`
+	ConfirmPassword string `json:"confirmPassword" validate:"required"`
+	PhoneNumber     string `json:"phoneNumber" validate:"required"`
+}
+
+type InvitationResponse struct {
+	UUID      uuid.UUID  `json:"uuid"`
+	Email     string     `json:"email"`
+	Role      string     `json:"role"`
+	InvitedBy uuid.UUID  `json:"invitedBy"`
+	ExpiredAt time.Time  `json:"expiredAt"`
+	CreatedAt *time.Time `json:"createdAt"`
+}
@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OrganizationRequest struct {
+	Name    string `json:"name" validate:"required,max=100"`
+	Address string `json:"address" validate:"max=255"`
+}
+
+type OrganizationResponse struct {
+	UUID      uuid.UUID  `json:"uuid"`
+	Name      string     `json:"name"`
+	Address   string     `json:"address"`
+	Role      string     `json:"role,omitempty"`
+	CreatedAt *time.Time `json:"createdAt"`
+}
+
+// OrganizationContext is the venue a request acts on, together with the
+// role the user holds there.
+type OrganizationContext struct {
+	ID   uint
+	UUID uuid.UUID
+	Name string
+	Role string
+}
+
+type MembershipRequest struct {
+	UserUUID string `json:"userUuid" validate:"required,uuid"`
+	RoleID   uint   `json:"roleId" validate:"required"`
+}
+
+type MembershipResponse struct {
+	User      UserResponse `json:"user"`
+	Role      string       `json:"role"`
+	CreatedAt *time.Time   `json:"createdAt"`
+}
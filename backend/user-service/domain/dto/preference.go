@@ -0,0 +1,29 @@
+package dto
+
+type PreferencesResponse struct {
+	Language          string                                  `json:"language"`
+	Timezone          string                                  `json:"timezone"`
+	PreferredPosition string                                  `json:"preferredPosition"`
+	Notifications     map[string]NotificationChannelsResponse `json:"notifications"`
+}
+
+type NotificationChannelsResponse struct {
+	Email bool `json:"email"`
+	SMS   bool `json:"sms"`
+	Push  bool `json:"push"`
+}
+
+// PreferencesPatchRequest only carries the fields the client wants to
+// change. Unknown fields are rejected when the body is decoded.
+type PreferencesPatchRequest struct {
+	Language          *string                                     `json:"language" validate:"omitempty,oneof=id en"`
+	Timezone          *string                                     `json:"timezone" validate:"omitempty,timezone"`
+	PreferredPosition *string                                     `json:"preferredPosition" validate:"omitempty,oneof=goalkeeper defender midfielder forward"`
+	Notifications     map[string]NotificationChannelsPatchRequest `json:"notifications" validate:"omitempty,dive,keys,oneof=booking_created booking_reminder booking_cancelled payment_success promotion,endkeys"`
+}
+
+type NotificationChannelsPatchRequest struct {
+	Email *bool `json:"email"`
+	SMS   *bool `json:"sms"`
+	Push  *bool `json:"push"`
+}
@@ -3,17 +3,27 @@ package dto
 import "github.com/google/uuid"
 
 type UserResponse struct {
-	UUID        uuid.UUID `json:"uuid"`
-	Name        string    `json:"name"`
-	Username    string    `json:"username"`
-	Email       string    `json:"email"`
-	Role        string    `json:"role,omitempty"`
-	PhoneNumber string    `json:"phoneNumber"`
+	UUID         uuid.UUID       `json:"uuid"`
+	Name         string          `json:"name"`
+	Username     string          `json:"username"`
+	Email        string          `json:"email"`
+	PendingEmail string          `json:"pendingEmail,omitempty"`
+	Role         string          `json:"role,omitempty"`
+	PhoneNumber  string          `json:"phoneNumber"`
+	Avatar       *AvatarResponse `json:"avatar,omitempty"`
+	Version      uint            `json:"-"`
+}
+
+type AvatarResponse struct {
+	Small  string `json:"small"`
+	Medium string `json:"medium"`
+	Large  string `json:"large"`
 }
 
 type LoginRequest struct {
-	Username string `json:"username" validate:"required"`
-	Password string `json:"password" validate:"required"`
+	Username         string  `json:"username" validate:"required"`
+	Password         string  `json:"password" validate:"required"`
+	OrganizationUUID *string `json:"organizationUuid,omitempty" validate:"omitempty,uuid"`
 }
 
 type LoginResponse struct {
@@ -35,12 +45,62 @@ type RegisterResponse struct {
 	User UserResponse `json:"user"`
 }
 
+// UpdateRequest replaces the profile. CurrentPassword is only read when the
+// email changes.
 type UpdateRequest struct {
-	Name            string  `json:"name" validate:"required"`
-	Username        string  `json:"username" validate:"required"`
-	Password        *string `json:"password,omitempty"`
-	ConfirmPassword *string `json:"confirmPassword,omitempty"`
-	Email           string  `json:"email" validate:"required,email"`
-	PhoneNumber     string  `json:"phoneNumber" validate:"required"`
-	RoleID          uint
+	Name            string `json:"name" validate:"required"`
+	Username        string `json:"username" validate:"required"`
+	Email           string `json:"email" validate:"required,email"`
+	PhoneNumber     string `json:"phoneNumber" validate:"required"`
+	CurrentPassword string `json:"currentPassword"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" validate:"required"`
+	NewPassword     string `json:"newPassword" validate:"required"`
+	ConfirmPassword string `json:"confirmPassword" validate:"required,eqfield=NewPassword"`
+}
+
+type EmailChangeTokenRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// PatchUserRequest is decoded from an RFC 7396 merge patch. A nil field
+// means the member was absent and the column is left untouched.
+// CurrentPassword is only read when the email changes.
+type PatchUserRequest struct {
+	Name            *string `json:"name" validate:"omitnil,min=1"`
+	Username        *string `json:"username" validate:"omitnil,min=1,max=20"`
+	Email           *string `json:"email" validate:"omitnil,email"`
+	PhoneNumber     *string `json:"phoneNumber" validate:"omitnil,min=1"`
+	CurrentPassword *string `json:"currentPassword"`
+}
+
+type UpdateRoleRequest struct {
+	RoleID uint `json:"roleId" validate:"required"`
+}
+
+type RoleAssignment struct {
+	UUID   string `json:"uuid" validate:"required,uuid"`
+	RoleID uint   `json:"roleId" validate:"required"`
+}
+
+type BulkUpdateRoleRequest struct {
+	Users []RoleAssignment `json:"users" validate:"required,min=1,max=100,dive"`
+}
+
+type AvailabilityRequest struct {
+	Username string `form:"username" validate:"required_without=Email,omitempty,max=20"`
+	Email    string `form:"email" validate:"required_without=Username,omitempty,email"`
+}
+
+type AvailabilityResponse struct {
+	Username *FieldAvailability `json:"username,omitempty"`
+	Email    *FieldAvailability `json:"email,omitempty"`
+}
+
+type FieldAvailability struct {
+	Value       string   `json:"value"`
+	Available   bool     `json:"available"`
+	Suggestions []string `json:"suggestions,omitempty"`
 }
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditLog struct {
+	ID          uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UUID        uuid.UUID  `json:"uuid" gorm:"type:uuid;not null"`
+	ActorUUID   uuid.UUID  `json:"actorUuid" gorm:"type:uuid;not null;index"`
+	SubjectUUID *uuid.UUID `json:"subjectUuid" gorm:"type:uuid;index"`
+	Action      string     `json:"action" gorm:"type:varchar(50);not null"`
+	Method      string     `json:"method" gorm:"type:varchar(10)"`
+	Path        string     `json:"path" gorm:"type:varchar(255)"`
+	StatusCode  int        `json:"statusCode"`
+	IPAddress   string     `json:"ipAddress" gorm:"type:varchar(45)"`
+	Description string     `json:"description" gorm:"type:varchar(255)"`
+	CreatedAt   *time.Time
+}
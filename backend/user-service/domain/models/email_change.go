@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailChange keeps a requested address pending until the link sent to it is
+// confirmed. The previous address can revert the change until
+// RevertExpiredAt.
+type EmailChange struct {
+	ID               uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UUID             uuid.UUID  `json:"uuid" gorm:"type:uuid;not null"`
+	UserID           uint       `json:"userId" gorm:"type:uint;not null;index"`
+	PreviousEmail    string     `json:"previousEmail" gorm:"type:varchar(100);not null"`
+	NewEmail         string     `json:"newEmail" gorm:"type:varchar(100);not null"`
+	ConfirmTokenHash string     `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	RevertTokenHash  string     `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	ExpiredAt        time.Time  `json:"expiredAt" gorm:"not null"`
+	RevertExpiredAt  time.Time  `json:"revertExpiredAt" gorm:"not null"`
+	ConfirmedAt      *time.Time `json:"confirmedAt"`
+	RevertedAt       *time.Time `json:"revertedAt"`
+	CreatedAt        *time.Time
+	UpdatedAt        *time.Time
+
+	User User `json:"user" gorm:"foreignKey:user_id;references:id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
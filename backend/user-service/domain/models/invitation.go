@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Invitation struct {
+	ID         uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UUID       uuid.UUID  `json:"uuid" gorm:"type:uuid;not null"`
+	Email      string     `json:"email" gorm:"type:varchar(100);not null"`
+	RoleID     uint       `json:"roleId" gorm:"type:uint;not null"`
+	TokenHash  string     `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	InvitedBy  uuid.UUID  `json:"invitedBy" gorm:"type:uuid;not null"`
+	ExpiredAt  time.Time  `json:"expiredAt" gorm:"not null"`
+	AcceptedAt *time.Time `json:"acceptedAt"`
+	RevokedAt  *time.Time `json:"revokedAt"`
+	CreatedAt  *time.Time
+	UpdatedAt  *time.Time
+
+	Role Role `json:"role" gorm:"foreignKey:role_id;references:id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+type Membership struct {
+	ID             uint `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID         uint `json:"userId" gorm:"type:uint;not null;uniqueIndex:idx_memberships_user_organization"`
+	OrganizationID uint `json:"organizationId" gorm:"type:uint;not null;uniqueIndex:idx_memberships_user_organization"`
+	RoleID         uint `json:"roleId" gorm:"type:uint;not null"`
+	CreatedAt      *time.Time
+	UpdatedAt      *time.Time
+
+	User         User         `json:"user" gorm:"foreignKey:user_id;references:id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Organization Organization `json:"organization" gorm:"foreignKey:organization_id;references:id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Role         Role         `json:"role" gorm:"foreignKey:role_id;references:id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
@@ -0,0 +1,17 @@
+package models
+
+// All lists every persisted model in dependency order. The SQL migrations
+// are verified against it.
+func All() []any {
+	return []any{
+		&Role{},
+		&User{},
+		&AuditLog{},
+		&Invitation{},
+		&Organization{},
+		&Membership{},
+		&UserPreference{},
+		&EmailChange{},
+		&PasswordHistory{},
+	}
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Organization struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UUID      uuid.UUID `json:"uuid" gorm:"type:uuid;not null"`
+	Name      string    `json:"name" gorm:"type:varchar(100);not null"`
+	Address   string    `json:"address" gorm:"type:varchar(255)"`
+	CreatedAt *time.Time
+	UpdatedAt *time.Time
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// PasswordHistory keeps the hashes a user recently replaced so they cannot be
+// chosen again.
+type PasswordHistory struct {
+	ID        uint   `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    uint   `json:"userId" gorm:"type:uint;not null;index"`
+	Password  string `json:"-" gorm:"type:varchar(255);not null"`
+	CreatedAt *time.Time
+
+	User User `json:"user" gorm:"foreignKey:user_id;references:id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
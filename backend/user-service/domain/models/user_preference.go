@@ -0,0 +1,52 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+type UserPreference struct {
+	ID          uint        `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID      uint        `json:"userId" gorm:"type:uint;not null;uniqueIndex"`
+	Preferences Preferences `json:"preferences" gorm:"type:jsonb;not null"`
+	CreatedAt   *time.Time
+	UpdatedAt   *time.Time
+
+	User User `json:"user" gorm:"foreignKey:user_id;references:id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+type Preferences struct {
+	Language          string                          `json:"language,omitempty"`
+	Timezone          string                          `json:"timezone,omitempty"`
+	PreferredPosition string                          `json:"preferredPosition,omitempty"`
+	Notifications     map[string]NotificationChannels `json:"notifications,omitempty"`
+}
+
+type NotificationChannels struct {
+	Email bool `json:"email"`
+	SMS   bool `json:"sms"`
+	Push  bool `json:"push"`
+}
+
+func (p Preferences) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+func (p *Preferences) Scan(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	case nil:
+		*p = Preferences{}
+		return nil
+	default:
+		return errors.New("unsupported preferences value")
+	}
+
+	return json.Unmarshal(data, p)
+}
@@ -3,20 +3,32 @@ package middlewares
 import (
 	"context"
 	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/hex"
 	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
+	"user-service/common/dbresolver"
+	"user-service/common/jwtkey"
+	"user-service/common/logger"
+	"user-service/common/metrics"
 	"user-service/common/response"
 	"user-service/config"
 	"user-service/constants"
 	"user-service/constants/custom-error"
+	"user-service/domain/dto"
+	"user-service/services"
 	"user-service/services/user"
 
 	"github.com/didip/tollbooth"
 	"github.com/didip/tollbooth/limiter"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
@@ -24,10 +36,11 @@ func HandlePanic() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if r := recover(); r != nil {
-				logrus.Errorf("Recovered from panic: %v", r)
+				logger.FromContext(c.Request.Context()).Errorf("Recovered from panic: %v", r)
 				c.JSON(http.StatusInternalServerError, response.Response{
-					Status:  constants.Error,
-					Message: customerror.ErrInternalServer.Error(),
+					Status:    constants.Error,
+					Message:   customerror.ErrInternalServer.Error(),
+					RequestID: logger.RequestID(c.Request.Context()),
 				})
 
 				c.Abort()
@@ -38,13 +51,111 @@ func HandlePanic() gin.HandlerFunc {
 	}
 }
 
+// RequestID keeps a well formed X-Request-ID sent by the caller or generates
+// one, stores it with the route in the request context and logs the request
+// once it has been served.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		requestID := c.GetHeader(constants.XRequestID)
+		if !isValidRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		ctx := context.WithValue(c.Request.Context(), constants.RequestID, requestID)
+		ctx = context.WithValue(ctx, constants.Route, c.FullPath())
+		c.Request = c.Request.WithContext(ctx)
+		c.Header(constants.XRequestID, requestID)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		entry := logger.FromContext(c.Request.Context()).WithFields(logrus.Fields{
+			"method":     c.Request.Method,
+			"path":       c.Request.URL.Path,
+			"status":     status,
+			"latency_ms": time.Since(start).Milliseconds(),
+			"client_ip":  c.ClientIP(),
+		})
+		if len(c.Errors) > 0 {
+			entry = entry.WithField("error", strings.Join(c.Errors.Errors(), "; "))
+		}
+
+		switch {
+		case status >= http.StatusInternalServerError:
+			entry.Error("request completed")
+		case status >= http.StatusBadRequest:
+			entry.Warn("request completed")
+		default:
+			entry.Info("request completed")
+		}
+	}
+}
+
+// DatabaseSession lets the repositories keep a request on the primary once it
+// has written, so it never reads a replica that is behind its own write.
+func DatabaseSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(dbresolver.WithSession(c.Request.Context()))
+		c.Next()
+	}
+}
+
+// Metrics records the count and latency of every request. Routes are
+// labelled by their template so path parameters do not create new series.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		status := strconv.Itoa(c.Writer.Status())
+		metrics.HTTPRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		metrics.HTTPDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// MetricsToken protects /metrics when it is served on the public port.
+func MetricsToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bearer := extractBearerToken(c.GetHeader(constants.Authorization))
+		if subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+			responseUnauthorized(c, customerror.ErrUnauthorized.Error())
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > 128 {
+		return false
+	}
+
+	for _, r := range requestID {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
 func RateLimiter(lmt *limiter.Limiter) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		err := tollbooth.LimitByRequest(lmt, c.Writer, c.Request)
 		if err != nil {
+			metrics.RateLimited.WithLabelValues(c.FullPath()).Inc()
 			c.JSON(http.StatusTooManyRequests, response.Response{
-				Status:  constants.Error,
-				Message: customerror.ErrTooManyRequest.Error(),
+				Status:    constants.Error,
+				Message:   customerror.ErrTooManyRequest.Error(),
+				RequestID: logger.RequestID(c.Request.Context()),
 			})
 
 			c.Abort()
@@ -54,6 +165,52 @@ func RateLimiter(lmt *limiter.Limiter) gin.HandlerFunc {
 	}
 }
 
+type corsPolicy struct {
+	any     bool
+	origins map[string]struct{}
+}
+
+func newCorsPolicy(cfg config.Cors) *corsPolicy {
+	policy := &corsPolicy{origins: map[string]struct{}{}}
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == "*" {
+			policy.any = true
+		}
+		policy.origins[origin] = struct{}{}
+	}
+
+	return policy
+}
+
+// CORS allows the configured origins. The policy is rebuilt when the
+// configuration is reloaded.
+func CORS() gin.HandlerFunc {
+	var policy atomic.Pointer[corsPolicy]
+	policy.Store(newCorsPolicy(config.Get().Cors))
+	config.Subscribe(func(prev, next *config.AppConfig) {
+		if !slices.Equal(prev.Cors.AllowedOrigins, next.Cors.AllowedOrigins) {
+			policy.Store(newCorsPolicy(next.Cors))
+			logrus.Infof("cors allowed origins changed to %v", next.Cors.AllowedOrigins)
+		}
+	})
+
+	return func(c *gin.Context) {
+		current := policy.Load()
+		origin := c.GetHeader("Origin")
+		if current.any {
+			c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		} else if _, ok := current.origins[origin]; ok {
+			c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+			c.Writer.Header().Add("Vary", "Origin")
+		}
+
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, x-service-name, x-api-key, x-request-at, x-organization-id, if-match, x-request-id, traceparent, tracestate")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "ETag, X-Request-ID")
+		c.Next()
+	}
+}
+
 func extractBearerToken(token string) string {
 	arrToken := strings.Split(token, " ")
 	if len(arrToken) == 2 {
@@ -65,8 +222,27 @@ func extractBearerToken(token string) string {
 
 func responseUnauthorized(c *gin.Context, message string) {
 	c.JSON(http.StatusUnauthorized, response.Response{
-		Status:  constants.Error,
-		Message: message,
+		Status:    constants.Error,
+		Message:   message,
+		RequestID: logger.RequestID(c.Request.Context()),
+	})
+	c.Abort()
+}
+
+func responseForbidden(c *gin.Context, message string) {
+	c.JSON(http.StatusForbidden, response.Response{
+		Status:    constants.Error,
+		Message:   message,
+		RequestID: logger.RequestID(c.Request.Context()),
+	})
+	c.Abort()
+}
+
+func responseBadRequest(c *gin.Context, message string) {
+	c.JSON(http.StatusBadRequest, response.Response{
+		Status:    constants.Error,
+		Message:   message,
+		RequestID: logger.RequestID(c.Request.Context()),
 	})
 	c.Abort()
 }
@@ -75,7 +251,7 @@ func validateAPIKey(c *gin.Context) error {
 	apiKey := c.GetHeader(constants.XApiKey)
 	requestAt := c.GetHeader(constants.XRequestAt)
 	serviceName := c.GetHeader(constants.XServiceName)
-	signatureKey := config.Config.SignatureKey
+	signatureKey := config.Get().SignatureKey
 
 	validateKey := fmt.Sprintf("%s:%s:%s", serviceName, signatureKey, requestAt)
 	hash := sha256.New()
@@ -83,22 +259,22 @@ func validateAPIKey(c *gin.Context) error {
 	resultHash := hex.EncodeToString(hash.Sum(nil))
 
 	if apiKey != resultHash {
-		logrus.Infof("ResultHash APIKey: %v", resultHash)
+		logger.FromContext(c.Request.Context()).Warnf("Invalid API key from service %q", serviceName)
 		return customerror.ErrUnauthorized
 	}
 
 	return nil
 }
 
-func validateBearerToken(c *gin.Context, token string) error {
+func validateBearerToken(c *gin.Context, token string, service services.IServiceRegistry) error {
 	if !strings.Contains(token, "Bearer") {
-		logrus.Errorf("Token is invalid")
+		logger.FromContext(c.Request.Context()).Errorf("Token is invalid")
 		return customerror.ErrUnauthorized
 	}
 
 	tokenStr := extractBearerToken(token)
 	if tokenStr == "" {
-		logrus.Errorf("Token is empty")
+		logger.FromContext(c.Request.Context()).Errorf("Token is empty")
 		return customerror.ErrUnauthorized
 	}
 
@@ -106,44 +282,81 @@ func validateBearerToken(c *gin.Context, token string) error {
 	tokenJwt, err := jwt.ParseWithClaims(tokenStr, claims, func(t *jwt.Token) (interface{}, error) {
 		_, ok := t.Method.(*jwt.SigningMethodHMAC)
 		if !ok {
-			logrus.Errorf("Token is invalid JWT")
+			logger.FromContext(c.Request.Context()).Errorf("Token is invalid JWT")
 			return nil, customerror.ErrInvalidToken
 		}
 
-		return []byte(config.Config.JwtSecretKey), nil
+		return jwtkey.Keyfunc(t)
 	})
 	if err != nil || !tokenJwt.Valid {
-		logrus.Errorf("Parsing token error: %v", err)
+		logger.FromContext(c.Request.Context()).Errorf("Parsing token error: %v", err)
 		return customerror.ErrUnauthorized
 	}
 
-	userLogin := c.Request.WithContext(context.WithValue(c.Request.Context(), constants.UserLogin, claims.User))
-	c.Request = userLogin
+	err = service.GetUser().ValidateTokenVersion(c.Request.Context(), claims.User.UUID.String(), claims.TokenVersion)
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Errorf("Token of user %s is no longer valid: %v", claims.User.UUID, err)
+		return customerror.ErrUnauthorized
+	}
+
+	ctx := context.WithValue(c.Request.Context(), constants.UserLogin, claims.User)
+	if claims.Organization != nil {
+		ctx = context.WithValue(ctx, constants.OrganizationClaim, claims.Organization)
+	}
+	if claims.IsImpersonated() {
+		ctx = context.WithValue(ctx, constants.Actor, claims.Actor)
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"actor":   claims.Actor.UUID,
+			"subject": claims.User.UUID,
+			"method":  c.Request.Method,
+			"path":    c.FullPath(),
+		}).Info("impersonated request")
+	}
+
+	c.Request = c.Request.WithContext(ctx)
 	c.Set(constants.Token, token)
+	if claims.Organization != nil {
+		c.Set(constants.OrganizationClaim, claims.Organization.String())
+	}
 
 	return nil
 }
 
-func Authenticate() gin.HandlerFunc {
+func Authenticate(service services.IServiceRegistry) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var err error
 		token := c.GetHeader(constants.Authorization)
 		if token == "" {
-			logrus.Errorf("Token is empty inside Authorization header")
+			logger.FromContext(c.Request.Context()).Errorf("Token is empty inside Authorization header")
 			responseUnauthorized(c, customerror.ErrUnauthorized.Error())
 			return
 		}
 
-		err = validateBearerToken(c, token)
+		err = validateBearerToken(c, token, service)
 		if err != nil {
-			logrus.Errorf("Token is invalid bearer token: %v", err)
+			logger.FromContext(c.Request.Context()).Errorf("Token is invalid bearer token: %v", err)
 			responseUnauthorized(c, err.Error())
 			return
 		}
 
 		err = validateAPIKey(c)
 		if err != nil {
-			logrus.Errorf("Validating API Key invalid: %v", err)
+			logger.FromContext(c.Request.Context()).Errorf("Validating API Key invalid: %v", err)
+			responseUnauthorized(c, err.Error())
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// AuthenticateService only checks the signed API key headers. It guards
+// internal endpoints called by other services rather than by users.
+func AuthenticateService() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := validateAPIKey(c)
+		if err != nil {
+			logger.FromContext(c.Request.Context()).Errorf("Validating API Key invalid: %v", err)
 			responseUnauthorized(c, err.Error())
 			return
 		}
@@ -151,3 +364,118 @@ func Authenticate() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+func CheckRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userLogin, ok := c.Request.Context().Value(constants.UserLogin).(*dto.UserResponse)
+		if !ok {
+			responseUnauthorized(c, customerror.ErrUnauthorized.Error())
+			return
+		}
+
+		for _, role := range roles {
+			if userLogin.Role == role {
+				c.Next()
+				return
+			}
+		}
+
+		logger.FromContext(c.Request.Context()).Errorf("Role %s is not allowed to access %s", userLogin.Role, c.FullPath())
+		responseForbidden(c, customerror.ErrForbidden.Error())
+	}
+}
+
+// BlockImpersonation rejects requests made with an impersonation token. It is
+// meant for sensitive operations such as password changes.
+func BlockImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if actor, ok := c.Request.Context().Value(constants.Actor).(*dto.Actor); ok {
+			logger.FromContext(c.Request.Context()).Errorf("Admin %s attempted %s %s while impersonating", actor.UUID, c.Request.Method, c.FullPath())
+			responseForbidden(c, customerror.ErrImpersonationNotPermitted.Error())
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// AuditImpersonation records every request made with an impersonation token.
+// It runs around the whole chain so the actor set by Authenticate is visible
+// once the handler has finished.
+func AuditImpersonation(service services.IServiceRegistry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		ctx := c.Request.Context()
+		actor, ok := ctx.Value(constants.Actor).(*dto.Actor)
+		if !ok {
+			return
+		}
+
+		userLogin := ctx.Value(constants.UserLogin).(*dto.UserResponse)
+		err := service.GetAudit().Record(context.WithoutCancel(ctx), &dto.AuditRequest{
+			ActorUUID:   actor.UUID,
+			SubjectUUID: &userLogin.UUID,
+			Action:      constants.AuditImpersonationRequest,
+			Method:      c.Request.Method,
+			Path:        c.Request.URL.Path,
+			StatusCode:  c.Writer.Status(),
+			IPAddress:   c.ClientIP(),
+		})
+		if err != nil {
+			logger.FromContext(c.Request.Context()).Errorf("failed to record impersonation audit: %v", err)
+		}
+	}
+}
+
+// ResolveOrganization picks the active venue from the X-Organization-ID
+// header, falling back to the org claim of the token, and stores it in the
+// request context. Repository queries are scoped to it from then on.
+func ResolveOrganization(service services.IServiceRegistry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		organizationUUID := c.GetHeader(constants.XOrganization)
+		if organizationUUID == "" {
+			organizationUUID = c.GetString(constants.OrganizationClaim)
+		}
+
+		if organizationUUID == "" {
+			responseBadRequest(c, customerror.ErrOrganizationRequired.Error())
+			return
+		}
+
+		if _, err := uuid.Parse(organizationUUID); err != nil {
+			responseBadRequest(c, customerror.ErrOrganizationNotFound.Error())
+			return
+		}
+
+		organization, err := service.GetOrganization().Resolve(c.Request.Context(), organizationUUID)
+		if err != nil {
+			logger.FromContext(c.Request.Context()).Errorf("Resolving organization %s failed: %v", organizationUUID, err)
+			responseForbidden(c, customerror.ErrForbidden.Error())
+			return
+		}
+
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), constants.Organization, organization))
+		c.Next()
+	}
+}
+
+func CheckOrganizationRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		organization, ok := c.Request.Context().Value(constants.Organization).(*dto.OrganizationContext)
+		if !ok {
+			responseBadRequest(c, customerror.ErrOrganizationRequired.Error())
+			return
+		}
+
+		for _, role := range roles {
+			if organization.Role == role {
+				c.Next()
+				return
+			}
+		}
+
+		logger.FromContext(c.Request.Context()).Errorf("Role %s at %s is not allowed to access %s", organization.Role, organization.UUID, c.FullPath())
+		responseForbidden(c, customerror.ErrForbidden.Error())
+	}
+}
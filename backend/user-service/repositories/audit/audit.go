@@ -0,0 +1,51 @@
+package audit
+
+import (
+	"context"
+	customErr "user-service/common/custom-error"
+	errConstant "user-service/constants/custom-error"
+	"user-service/domain/dto"
+	"user-service/domain/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+type IAuditRepository interface {
+	Create(context.Context, *dto.AuditRequest) (*models.AuditLog, error)
+}
+
+func NewAuditRepository(db *gorm.DB) IAuditRepository {
+	return &AuditRepository{
+		db: db,
+	}
+}
+
+func (ar *AuditRepository) Create(ctx context.Context, req *dto.AuditRequest) (*models.AuditLog, error) {
+	auditLog := models.AuditLog{
+		UUID:        uuid.New(),
+		ActorUUID:   req.ActorUUID,
+		SubjectUUID: req.SubjectUUID,
+		Action:      req.Action,
+		Method:      req.Method,
+		Path:        req.Path,
+		StatusCode:  req.StatusCode,
+		IPAddress:   req.IPAddress,
+		Description: req.Description,
+	}
+
+	err := ar.db.
+		WithContext(ctx).
+		Model(&models.AuditLog{}).
+		Create(&auditLog).
+		Error
+	if err != nil {
+		return nil, customErr.WrapError(ctx, errConstant.ErrSQL)
+	}
+
+	return &auditLog, nil
+}
@@ -0,0 +1,305 @@
+package emailchange
+
+import (
+	"context"
+	"errors"
+	"time"
+	customErr "user-service/common/custom-error"
+	errConstant "user-service/constants/custom-error"
+	"user-service/domain/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type EmailChangeRepository struct {
+	db *gorm.DB
+}
+
+type IEmailChangeRepository interface {
+	Request(context.Context, *models.EmailChange, string, uint, map[string]any) (*models.User, error)
+	FindPendingByConfirmTokenHash(context.Context, string) (*models.EmailChange, error)
+	FindByRevertTokenHash(context.Context, string) (*models.EmailChange, error)
+	IsEmailReserved(context.Context, string) (bool, error)
+	Confirm(context.Context, *models.EmailChange) (*models.User, error)
+	Revert(context.Context, *models.EmailChange) (*models.User, error)
+}
+
+func NewEmailChangeRepository(db *gorm.DB) IEmailChangeRepository {
+	return &EmailChangeRepository{
+		db: db,
+	}
+}
+
+func pending(db *gorm.DB) *gorm.DB {
+	return db.Where("confirmed_at IS NULL AND reverted_at IS NULL")
+}
+
+// Request stores a new request together with the other changes to the user,
+// so neither is saved without the other. Like UserRepository.UpdateFields the
+// user is only written at the version the caller read. Any earlier pending
+// request of the user is closed, so only the latest confirmation link works.
+func (er *EmailChangeRepository) Request(ctx context.Context, change *models.EmailChange, userUuid string, version uint, fields map[string]any) (*models.User, error) {
+	var user models.User
+
+	err := er.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		fields["version"] = gorm.Expr("version + 1")
+
+		result := tx.
+			Model(&models.User{}).
+			Where("uuid = ? AND version = ?", userUuid, version).
+			Updates(fields)
+		if result.Error != nil {
+			return customErr.WrapError(ctx, errConstant.ErrSQL)
+		}
+
+		if result.RowsAffected == 0 {
+			return errConstant.ErrVersionMismatch
+		}
+
+		err := tx.
+			Model(&models.EmailChange{}).
+			Scopes(pending).
+			Where("user_id = ?", change.UserID).
+			Update("reverted_at", time.Now()).
+			Error
+		if err != nil {
+			return customErr.WrapError(ctx, errConstant.ErrSQL)
+		}
+
+		err = tx.Model(&models.EmailChange{}).Create(change).Error
+		if err != nil {
+			return customErr.WrapError(ctx, errConstant.ErrSQL)
+		}
+
+		return reload(tx, change.UserID, &user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (er *EmailChangeRepository) FindPendingByConfirmTokenHash(ctx context.Context, tokenHash string) (*models.EmailChange, error) {
+	var change models.EmailChange
+
+	err := er.db.
+		WithContext(ctx).
+		Model(&models.EmailChange{}).
+		Preload("User").
+		Scopes(pending).
+		Where("confirm_token_hash = ?", tokenHash).
+		First(&change).
+		Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errConstant.ErrEmailChangeNotFound
+		}
+
+		return nil, customErr.WrapError(ctx, errConstant.ErrSQL)
+	}
+
+	return &change, nil
+}
+
+func (er *EmailChangeRepository) FindByRevertTokenHash(ctx context.Context, tokenHash string) (*models.EmailChange, error) {
+	var change models.EmailChange
+
+	err := er.db.
+		WithContext(ctx).
+		Model(&models.EmailChange{}).
+		Preload("User").
+		Where("revert_token_hash = ? AND reverted_at IS NULL", tokenHash).
+		First(&change).
+		Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errConstant.ErrEmailChangeNotFound
+		}
+
+		return nil, customErr.WrapError(ctx, errConstant.ErrSQL)
+	}
+
+	return &change, nil
+}
+
+// IsEmailReserved reports whether an address is waiting for confirmation, or
+// was just given up and can still be reclaimed through a revert link.
+func (er *EmailChangeRepository) IsEmailReserved(ctx context.Context, email string) (bool, error) {
+	var count int64
+
+	now := time.Now()
+	err := er.db.
+		WithContext(ctx).
+		Model(&models.EmailChange{}).
+		Where("reverted_at IS NULL").
+		Where(
+			er.db.
+				Where("LOWER(new_email) = LOWER(?) AND confirmed_at IS NULL AND expired_at > ?", email, now).
+				Or("LOWER(previous_email) = LOWER(?) AND confirmed_at IS NOT NULL AND revert_expired_at > ?", email, now),
+		).
+		Count(&count).
+		Error
+	if err != nil {
+		return false, customErr.WrapError(ctx, errConstant.ErrSQL)
+	}
+
+	return count > 0, nil
+}
+
+// Confirm moves the user to the new address. The request row is locked so a
+// confirmation link can only be used once.
+func (er *EmailChangeRepository) Confirm(ctx context.Context, change *models.EmailChange) (*models.User, error) {
+	var user models.User
+
+	err := er.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		locked, err := lock(tx, change.ID, "confirmed_at IS NULL AND reverted_at IS NULL")
+		if err != nil {
+			return err
+		}
+
+		if locked.ExpiredAt.Before(time.Now()) {
+			return errConstant.ErrEmailChangeExpired
+		}
+
+		err = ensureEmailFree(tx, locked.UserID, locked.NewEmail)
+		if err != nil {
+			return err
+		}
+
+		err = updateUser(tx, locked.UserID, map[string]any{
+			"email":   locked.NewEmail,
+			"version": gorm.Expr("version + 1"),
+		})
+		if err != nil {
+			return err
+		}
+
+		err = tx.
+			Model(&models.EmailChange{}).
+			Where("id = ?", locked.ID).
+			Update("confirmed_at", time.Now()).
+			Error
+		if err != nil {
+			return customErr.WrapError(ctx, errConstant.ErrSQL)
+		}
+
+		return reload(tx, locked.UserID, &user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// Revert cancels a pending request or restores the previous address of a
+// confirmed one. Either way every issued token is invalidated, since a revert
+// means the owner of the old address did not ask for the change.
+func (er *EmailChangeRepository) Revert(ctx context.Context, change *models.EmailChange) (*models.User, error) {
+	var user models.User
+
+	err := er.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		locked, err := lock(tx, change.ID, "reverted_at IS NULL")
+		if err != nil {
+			return err
+		}
+
+		if locked.RevertExpiredAt.Before(time.Now()) {
+			return errConstant.ErrEmailChangeExpired
+		}
+
+		fields := map[string]any{
+			"token_version": gorm.Expr("token_version + 1"),
+			"version":       gorm.Expr("version + 1"),
+		}
+		if locked.ConfirmedAt != nil {
+			err = ensureEmailFree(tx, locked.UserID, locked.PreviousEmail)
+			if err != nil {
+				return err
+			}
+
+			fields["email"] = locked.PreviousEmail
+		}
+
+		err = updateUser(tx, locked.UserID, fields)
+		if err != nil {
+			return err
+		}
+
+		err = tx.
+			Model(&models.EmailChange{}).
+			Where("id = ?", locked.ID).
+			Update("reverted_at", time.Now()).
+			Error
+		if err != nil {
+			return customErr.WrapError(ctx, errConstant.ErrSQL)
+		}
+
+		return reload(tx, locked.UserID, &user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func lock(tx *gorm.DB, id uint, condition string) (*models.EmailChange, error) {
+	var change models.EmailChange
+
+	err := tx.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).
+		Where(condition).
+		First(&change).
+		Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errConstant.ErrEmailChangeNotFound
+		}
+
+		return nil, customErr.WrapError(tx.Statement.Context, errConstant.ErrSQL)
+	}
+
+	return &change, nil
+}
+
+// ensureEmailFree makes sure no other account took the address while the
+// request was waiting.
+func ensureEmailFree(tx *gorm.DB, userID uint, email string) error {
+	var count int64
+	err := tx.
+		Model(&models.User{}).
+		Where("LOWER(email) = LOWER(?) AND id <> ?", email, userID).
+		Count(&count).
+		Error
+	if err != nil {
+		return customErr.WrapError(tx.Statement.Context, errConstant.ErrSQL)
+	}
+
+	if count > 0 {
+		return errConstant.ErrEmailExist
+	}
+
+	return nil
+}
+
+func updateUser(tx *gorm.DB, userID uint, fields map[string]any) error {
+	err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(fields).Error
+	if err != nil {
+		return customErr.WrapError(tx.Statement.Context, errConstant.ErrSQL)
+	}
+
+	return nil
+}
+
+func reload(tx *gorm.DB, userID uint, user *models.User) error {
+	err := tx.Preload("Role").First(user, userID).Error
+	if err != nil {
+		return customErr.WrapError(tx.Statement.Context, errConstant.ErrSQL)
+	}
+
+	return nil
+}
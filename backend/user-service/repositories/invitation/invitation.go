@@ -0,0 +1,190 @@
+package invitation
+
+import (
+	"context"
+	"errors"
+	"time"
+	customErr "user-service/common/custom-error"
+	errConstant "user-service/constants/custom-error"
+	"user-service/domain/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InvitationRepository struct {
+	db *gorm.DB
+}
+
+type IInvitationRepository interface {
+	Create(context.Context, *models.Invitation) (*models.Invitation, error)
+	FindPending(context.Context) ([]models.Invitation, error)
+	FindPendingByEmail(context.Context, string) (*models.Invitation, error)
+	FindPendingByTokenHash(context.Context, string) (*models.Invitation, error)
+	FindByUUID(context.Context, string) (*models.Invitation, error)
+	Revoke(context.Context, string) error
+	Accept(context.Context, *models.Invitation, *models.User) (*models.User, error)
+}
+
+func NewInvitationRepository(db *gorm.DB) IInvitationRepository {
+	return &InvitationRepository{
+		db: db,
+	}
+}
+
+func pending(db *gorm.DB) *gorm.DB {
+	return db.Where("accepted_at IS NULL AND revoked_at IS NULL AND expired_at > ?", time.Now())
+}
+
+func (ir *InvitationRepository) Create(ctx context.Context, invitation *models.Invitation) (*models.Invitation, error) {
+	err := ir.db.
+		WithContext(ctx).
+		Model(&models.Invitation{}).
+		Create(invitation).
+		Error
+	if err != nil {
+		return nil, customErr.WrapError(ctx, errConstant.ErrSQL)
+	}
+
+	return invitation, nil
+}
+
+func (ir *InvitationRepository) FindPending(ctx context.Context) ([]models.Invitation, error) {
+	var invitations []models.Invitation
+
+	err := ir.db.
+		WithContext(ctx).
+		Model(&models.Invitation{}).
+		Preload("Role").
+		Scopes(pending).
+		Order("created_at DESC").
+		Find(&invitations).
+		Error
+	if err != nil {
+		return nil, customErr.WrapError(ctx, errConstant.ErrSQL)
+	}
+
+	return invitations, nil
+}
+
+func (ir *InvitationRepository) FindPendingByEmail(ctx context.Context, email string) (*models.Invitation, error) {
+	var invitation models.Invitation
+
+	err := ir.db.
+		WithContext(ctx).
+		Model(&models.Invitation{}).
+		Preload("Role").
+		Scopes(pending).
+		Where("LOWER(email) = LOWER(?)", email).
+		First(&invitation).
+		Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errConstant.ErrInvitationNotFound
+		}
+
+		return nil, customErr.WrapError(ctx, errConstant.ErrSQL)
+	}
+
+	return &invitation, nil
+}
+
+func (ir *InvitationRepository) FindPendingByTokenHash(ctx context.Context, tokenHash string) (*models.Invitation, error) {
+	var invitation models.Invitation
+
+	err := ir.db.
+		WithContext(ctx).
+		Model(&models.Invitation{}).
+		Preload("Role").
+		Where("token_hash = ? AND accepted_at IS NULL AND revoked_at IS NULL", tokenHash).
+		First(&invitation).
+		Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errConstant.ErrInvitationNotFound
+		}
+
+		return nil, customErr.WrapError(ctx, errConstant.ErrSQL)
+	}
+
+	return &invitation, nil
+}
+
+func (ir *InvitationRepository) FindByUUID(ctx context.Context, uuid string) (*models.Invitation, error) {
+	var invitation models.Invitation
+
+	err := ir.db.
+		WithContext(ctx).
+		Model(&models.Invitation{}).
+		Preload("Role").
+		Where("uuid = ?", uuid).
+		First(&invitation).
+		Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errConstant.ErrInvitationNotFound
+		}
+
+		return nil, customErr.WrapError(ctx, errConstant.ErrSQL)
+	}
+
+	return &invitation, nil
+}
+
+func (ir *InvitationRepository) Revoke(ctx context.Context, uuid string) error {
+	result := ir.db.
+		WithContext(ctx).
+		Model(&models.Invitation{}).
+		Where("uuid = ? AND accepted_at IS NULL AND revoked_at IS NULL", uuid).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return customErr.WrapError(ctx, errConstant.ErrSQL)
+	}
+
+	if result.RowsAffected == 0 {
+		return errConstant.ErrInvitationNotFound
+	}
+
+	return nil
+}
+
+// Accept creates the invited user and marks the invitation as accepted in a
+// single transaction. The invitation row is locked so it can only be used once.
+func (ir *InvitationRepository) Accept(ctx context.Context, invitation *models.Invitation, user *models.User) (*models.User, error) {
+	err := ir.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var locked models.Invitation
+		err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", invitation.ID).
+			First(&locked).
+			Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errConstant.ErrInvitationNotFound
+			}
+
+			return customErr.WrapError(ctx, errConstant.ErrSQL)
+		}
+
+		err = tx.Model(&models.User{}).Create(user).Error
+		if err != nil {
+			return customErr.WrapError(ctx, errConstant.ErrSQL)
+		}
+
+		err = tx.
+			Model(&models.Invitation{}).
+			Where("id = ?", invitation.ID).
+			Update("accepted_at", time.Now()).
+			Error
+		if err != nil {
+			return customErr.WrapError(ctx, errConstant.ErrSQL)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
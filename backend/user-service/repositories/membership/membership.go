@@ -0,0 +1,129 @@
+package membership
+
+import (
+	"context"
+	"errors"
+	customErr "user-service/common/custom-error"
+	errConstant "user-service/constants/custom-error"
+	"user-service/domain/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MembershipRepository queries are scoped to the active organization by the
+// callbacks in repositories/scope, so none of the methods below filter on
+// organization_id themselves unless they run before a venue is resolved.
+type MembershipRepository struct {
+	db *gorm.DB
+}
+
+type IMembershipRepository interface {
+	FindByUserAndOrganization(context.Context, string, uint) (*models.Membership, error)
+	FindByUser(context.Context, string) ([]models.Membership, error)
+	FindAll(context.Context) ([]models.Membership, error)
+	Upsert(context.Context, *models.Membership) (*models.Membership, error)
+	Delete(context.Context, uint) error
+}
+
+func NewMembershipRepository(db *gorm.DB) IMembershipRepository {
+	return &MembershipRepository{
+		db: db,
+	}
+}
+
+func (mr *MembershipRepository) FindByUserAndOrganization(
+	ctx context.Context,
+	userUUID string,
+	organizationID uint,
+) (*models.Membership, error) {
+	var membership models.Membership
+
+	err := mr.db.
+		WithContext(ctx).
+		Model(&models.Membership{}).
+		Preload("Role").
+		Joins("JOIN users ON users.id = memberships.user_id").
+		Where("users.uuid = ? AND memberships.organization_id = ?", userUUID, organizationID).
+		First(&membership).
+		Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errConstant.ErrMembershipNotFound
+		}
+
+		return nil, customErr.WrapError(ctx, errConstant.ErrSQL)
+	}
+
+	return &membership, nil
+}
+
+func (mr *MembershipRepository) FindByUser(ctx context.Context, userUUID string) ([]models.Membership, error) {
+	var memberships []models.Membership
+
+	err := mr.db.
+		WithContext(ctx).
+		Model(&models.Membership{}).
+		Preload("Role").
+		Preload("Organization").
+		Joins("JOIN users ON users.id = memberships.user_id").
+		Where("users.uuid = ?", userUUID).
+		Find(&memberships).
+		Error
+	if err != nil {
+		return nil, customErr.WrapError(ctx, errConstant.ErrSQL)
+	}
+
+	return memberships, nil
+}
+
+func (mr *MembershipRepository) FindAll(ctx context.Context) ([]models.Membership, error) {
+	var memberships []models.Membership
+
+	err := mr.db.
+		WithContext(ctx).
+		Model(&models.Membership{}).
+		Preload("Role").
+		Preload("User").
+		Order("created_at ASC").
+		Find(&memberships).
+		Error
+	if err != nil {
+		return nil, customErr.WrapError(ctx, errConstant.ErrSQL)
+	}
+
+	return memberships, nil
+}
+
+func (mr *MembershipRepository) Upsert(ctx context.Context, membership *models.Membership) (*models.Membership, error) {
+	err := mr.db.
+		WithContext(ctx).
+		Model(&models.Membership{}).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "organization_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"role_id", "updated_at"}),
+		}).
+		Create(membership).
+		Error
+	if err != nil {
+		return nil, customErr.WrapError(ctx, errConstant.ErrSQL)
+	}
+
+	return membership, nil
+}
+
+func (mr *MembershipRepository) Delete(ctx context.Context, userID uint) error {
+	result := mr.db.
+		WithContext(ctx).
+		Where("user_id = ?", userID).
+		Delete(&models.Membership{})
+	if result.Error != nil {
+		return customErr.WrapError(ctx, errConstant.ErrSQL)
+	}
+
+	if result.RowsAffected == 0 {
+		return errConstant.ErrMembershipNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package organization
+
+import (
+	"context"
+	"errors"
+	customErr "user-service/common/custom-error"
+	errConstant "user-service/constants/custom-error"
+	"user-service/domain/dto"
+	"user-service/domain/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type OrganizationRepository struct {
+	db *gorm.DB
+}
+
+type IOrganizationRepository interface {
+	Create(context.Context, *dto.OrganizationRequest) (*models.Organization, error)
+	FindAll(context.Context) ([]models.Organization, error)
+	FindByUUID(context.Context, string) (*models.Organization, error)
+}
+
+func NewOrganizationRepository(db *gorm.DB) IOrganizationRepository {
+	return &OrganizationRepository{
+		db: db,
+	}
+}
+
+func (or *OrganizationRepository) Create(ctx context.Context, req *dto.OrganizationRequest) (*models.Organization, error) {
+	organization := models.Organization{
+		UUID:    uuid.New(),
+		Name:    req.Name,
+		Address: req.Address,
+	}
+
+	err := or.db.
+		WithContext(ctx).
+		Model(&models.Organization{}).
+		Create(&organization).
+		Error
+	if err != nil {
+		return nil, customErr.WrapError(ctx, errConstant.ErrSQL)
+	}
+
+	return &organization, nil
+}
+
+func (or *OrganizationRepository) FindAll(ctx context.Context) ([]models.Organization, error) {
+	var organizations []models.Organization
+
+	err := or.db.
+		WithContext(ctx).
+		Model(&models.Organization{}).
+		Order("name ASC").
+		Find(&organizations).
+		Error
+	if err != nil {
+		return nil, customErr.WrapError(ctx, errConstant.ErrSQL)
+	}
+
+	return organizations, nil
+}
+
+func (or *OrganizationRepository) FindByUUID(ctx context.Context, uuid string) (*models.Organization, error) {
+	var organization models.Organization
+
+	err := or.db.
+		WithContext(ctx).
+		Model(&models.Organization{}).
+		Where("uuid = ?", uuid).
+		First(&organization).
+		Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errConstant.ErrOrganizationNotFound
+		}
+
+		return nil, customErr.WrapError(ctx, errConstant.ErrSQL)
+	}
+
+	return &organization, nil
+}
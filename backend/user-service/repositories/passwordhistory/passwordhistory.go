@@ -0,0 +1,42 @@
+package passwordhistory
+
+import (
+	"context"
+	customErr "user-service/common/custom-error"
+	errConstant "user-service/constants/custom-error"
+	"user-service/domain/models"
+
+	"gorm.io/gorm"
+)
+
+type PasswordHistoryRepository struct {
+	db *gorm.DB
+}
+
+type IPasswordHistoryRepository interface {
+	FindRecent(context.Context, uint, int) ([]models.PasswordHistory, error)
+}
+
+func NewPasswordHistoryRepository(db *gorm.DB) IPasswordHistoryRepository {
+	return &PasswordHistoryRepository{
+		db: db,
+	}
+}
+
+func (pr *PasswordHistoryRepository) FindRecent(ctx context.Context, userID uint, limit int) ([]models.PasswordHistory, error) {
+	var histories []models.PasswordHistory
+
+	err := pr.db.
+		WithContext(ctx).
+		Model(&models.PasswordHistory{}).
+		Where("user_id = ?", userID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&histories).
+		Error
+	if err != nil {
+		return nil, customErr.WrapError(ctx, errConstant.ErrSQL)
+	}
+
+	return histories, nil
+}
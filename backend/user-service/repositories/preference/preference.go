@@ -0,0 +1,69 @@
+package preference
+
+import (
+	"context"
+	"errors"
+	customErr "user-service/common/custom-error"
+	errConstant "user-service/constants/custom-error"
+	"user-service/domain/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PreferenceRepository struct {
+	db *gorm.DB
+}
+
+type IPreferenceRepository interface {
+	FindByUserID(context.Context, uint) (*models.UserPreference, error)
+	Upsert(context.Context, uint, models.Preferences) (*models.UserPreference, error)
+}
+
+func NewPreferenceRepository(db *gorm.DB) IPreferenceRepository {
+	return &PreferenceRepository{
+		db: db,
+	}
+}
+
+func (pr *PreferenceRepository) FindByUserID(ctx context.Context, userID uint) (*models.UserPreference, error) {
+	var preference models.UserPreference
+
+	err := pr.db.
+		WithContext(ctx).
+		Model(&models.UserPreference{}).
+		Where("user_id = ?", userID).
+		First(&preference).
+		Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errConstant.ErrPreferenceNotFound
+		}
+
+		return nil, customErr.WrapError(ctx, errConstant.ErrSQL)
+	}
+
+	return &preference, nil
+}
+
+func (pr *PreferenceRepository) Upsert(ctx context.Context, userID uint, preferences models.Preferences) (*models.UserPreference, error) {
+	preference := models.UserPreference{
+		UserID:      userID,
+		Preferences: preferences,
+	}
+
+	err := pr.db.
+		WithContext(ctx).
+		Model(&models.UserPreference{}).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"preferences", "updated_at"}),
+		}).
+		Create(&preference).
+		Error
+	if err != nil {
+		return nil, customErr.WrapError(ctx, errConstant.ErrSQL)
+	}
+
+	return &preference, nil
+}
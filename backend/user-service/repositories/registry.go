@@ -3,23 +3,74 @@ package repositories
 import (
 	"gorm.io/gorm"
 
+	"user-service/common/dbresolver"
+	"user-service/repositories/audit"
+	"user-service/repositories/emailchange"
+	"user-service/repositories/invitation"
+	"user-service/repositories/membership"
+	"user-service/repositories/organization"
+	"user-service/repositories/passwordhistory"
+	"user-service/repositories/preference"
+	"user-service/repositories/role"
 	"user-service/repositories/user"
 )
 
 type Registry struct {
-	db *gorm.DB
+	db       *gorm.DB
+	resolver *dbresolver.Resolver
 }
 
 type IRepositoryRegistry interface {
 	GetUser() user.IUserRepository
+	GetAudit() audit.IAuditRepository
+	GetRole() role.IRoleRepository
+	GetInvitation() invitation.IInvitationRepository
+	GetOrganization() organization.IOrganizationRepository
+	GetMembership() membership.IMembershipRepository
+	GetPreference() preference.IPreferenceRepository
+	GetEmailChange() emailchange.IEmailChangeRepository
+	GetPasswordHistory() passwordhistory.IPasswordHistoryRepository
 }
 
-func NewRepositoryRegistry(db *gorm.DB) IRepositoryRegistry {
+func NewRepositoryRegistry(resolver *dbresolver.Resolver) IRepositoryRegistry {
 	return &Registry{
-		db: db,
+		db:       resolver.Primary(),
+		resolver: resolver,
 	}
 }
 
 func (r *Registry) GetUser() user.IUserRepository {
-	return user.NewUserRepository(r.db)
+	return user.NewUserRepository(r.resolver)
+}
+
+func (r *Registry) GetAudit() audit.IAuditRepository {
+	return audit.NewAuditRepository(r.db)
+}
+
+func (r *Registry) GetRole() role.IRoleRepository {
+	return role.NewRoleRepository(r.db)
+}
+
+func (r *Registry) GetInvitation() invitation.IInvitationRepository {
+	return invitation.NewInvitationRepository(r.db)
+}
+
+func (r *Registry) GetOrganization() organization.IOrganizationRepository {
+	return organization.NewOrganizationRepository(r.db)
+}
+
+func (r *Registry) GetMembership() membership.IMembershipRepository {
+	return membership.NewMembershipRepository(r.db)
+}
+
+func (r *Registry) GetPreference() preference.IPreferenceRepository {
+	return preference.NewPreferenceRepository(r.db)
+}
+
+func (r *Registry) GetEmailChange() emailchange.IEmailChangeRepository {
+	return emailchange.NewEmailChangeRepository(r.db)
+}
+
+func (r *Registry) GetPasswordHistory() passwordhistory.IPasswordHistoryRepository {
+	return passwordhistory.NewPasswordHistoryRepository(r.db)
 }
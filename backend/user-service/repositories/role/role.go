@@ -0,0 +1,44 @@
+package role
+
+import (
+	"context"
+	"errors"
+	customErr "user-service/common/custom-error"
+	errConstant "user-service/constants/custom-error"
+	"user-service/domain/models"
+
+	"gorm.io/gorm"
+)
+
+type RoleRepository struct {
+	db *gorm.DB
+}
+
+type IRoleRepository interface {
+	FindByID(context.Context, uint) (*models.Role, error)
+}
+
+func NewRoleRepository(db *gorm.DB) IRoleRepository {
+	return &RoleRepository{
+		db: db,
+	}
+}
+
+func (rr *RoleRepository) FindByID(ctx context.Context, id uint) (*models.Role, error) {
+	var role models.Role
+
+	err := rr.db.
+		WithContext(ctx).
+		Where("id = ?", id).
+		First(&role).
+		Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errConstant.ErrRoleNotFound
+		}
+
+		return nil, customErr.WrapError(ctx, errConstant.ErrSQL)
+	}
+
+	return &role, nil
+}
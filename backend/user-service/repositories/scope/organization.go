@@ -0,0 +1,102 @@
+package scope
+
+import (
+	"reflect"
+	"user-service/constants"
+	"user-service/domain/dto"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const organizationField = "OrganizationID"
+
+// RegisterOrganizationScope installs GORM callbacks that scope every model
+// with an OrganizationID field to the venue resolved for the request. Reads,
+// updates and deletes get an extra WHERE condition and creates get the
+// organization filled in. Statements without a venue in their context are
+// left untouched.
+func RegisterOrganizationScope(db *gorm.DB) error {
+	callback := db.Callback()
+
+	err := callback.Query().Before("gorm:query").Register("organization:query", filterByOrganization)
+	if err != nil {
+		return err
+	}
+
+	err = callback.Row().Before("gorm:row").Register("organization:row", filterByOrganization)
+	if err != nil {
+		return err
+	}
+
+	err = callback.Update().Before("gorm:update").Register("organization:update", filterByOrganization)
+	if err != nil {
+		return err
+	}
+
+	err = callback.Delete().Before("gorm:delete").Register("organization:delete", filterByOrganization)
+	if err != nil {
+		return err
+	}
+
+	return callback.Create().Before("gorm:create").Register("organization:create", assignOrganization)
+}
+
+func organizationFromStatement(db *gorm.DB) (*dto.OrganizationContext, bool) {
+	if db.Statement.Context == nil || db.Statement.Schema == nil {
+		return nil, false
+	}
+
+	organization, ok := db.Statement.Context.Value(constants.Organization).(*dto.OrganizationContext)
+	return organization, ok
+}
+
+func filterByOrganization(db *gorm.DB) {
+	organization, ok := organizationFromStatement(db)
+	if !ok {
+		return
+	}
+
+	field := db.Statement.Schema.LookUpField(organizationField)
+	if field == nil {
+		return
+	}
+
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{
+			Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName},
+			Value:  organization.ID,
+		},
+	}})
+}
+
+func assignOrganization(db *gorm.DB) {
+	organization, ok := organizationFromStatement(db)
+	if !ok {
+		return
+	}
+
+	field := db.Statement.Schema.LookUpField(organizationField)
+	if field == nil {
+		return
+	}
+
+	ctx := db.Statement.Context
+	assign := func(value reflect.Value) {
+		if _, isZero := field.ValueOf(ctx, value); isZero {
+			err := field.Set(ctx, value, organization.ID)
+			if err != nil {
+				_ = db.AddError(err)
+			}
+		}
+	}
+
+	switch value := db.Statement.ReflectValue; value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			assign(reflect.Indirect(value.Index(i)))
+		}
+	case reflect.Struct:
+		assign(value)
+	}
+}
@@ -4,48 +4,71 @@ import (
 	"context"
 	"errors"
 	customErr "user-service/common/custom-error"
+	"user-service/common/dbresolver"
+	"user-service/constants"
 	errConstant "user-service/constants/custom-error"
 	"user-service/domain/dto"
 	"user-service/domain/models"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository struct {
-	db *gorm.DB
+	db       *gorm.DB
+	resolver *dbresolver.Resolver
 }
 
 type IUserRepository interface {
 	Register(context.Context, *dto.RegisterRequest) (*models.User, error)
-	Update(context.Context, *dto.UpdateRequest, string) (*models.User, error)
+	Update(context.Context, *dto.UpdateRequest, string, uint) (*models.User, error)
+	UpdateFields(context.Context, string, uint, map[string]any) (*models.User, error)
 	FindByUsername(context.Context, string) (*models.User, error)
 	FindByEmail(context.Context, string) (*models.User, error)
 	FindByUUID(context.Context, string) (*models.User, error)
+	FindByUUIDFromPrimary(context.Context, string) (*models.User, error)
+	UpdateRoles(context.Context, []dto.RoleAssignment) ([]RoleUpdate, error)
+	UpdateAvatar(context.Context, string, string) (*models.User, error)
+	UpdatePassword(context.Context, uint, string) (*models.User, error)
+	FindExistingUsernames(context.Context, []string) ([]string, error)
+	FindTokenVersion(context.Context, string) (uint, error)
 }
 
-func NewUserRepository(db *gorm.DB) IUserRepository {
+// RoleUpdate is the outcome of one role assignment. Changed is false when the
+// user already had the role.
+type RoleUpdate struct {
+	User    models.User
+	Changed bool
+}
+
+// NewUserRepository writes through the primary of resolver and reads from
+// its replicas.
+func NewUserRepository(resolver *dbresolver.Resolver) IUserRepository {
 	return &UserRepository{
-		db: db,
+		db:       resolver.Primary(),
+		resolver: resolver,
 	}
 }
 
 func (ur *UserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
 
-	err := ur.db.
-		WithContext(ctx).
-		Model(&models.User{}).
-		Preload("Role").
-		Where("email = ?", email).
-		First(&user).
-		Error
+	err := ur.resolver.Read(ctx, func(db *gorm.DB) error {
+		return db.
+			WithContext(ctx).
+			Model(&models.User{}).
+			Preload("Role").
+			Where("LOWER(email) = LOWER(?)", email).
+			First(&user).
+			Error
+	})
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errConstant.ErrUserNotFound
 		}
 
-		return nil, customErr.WrapError(errConstant.ErrSQL)
+		return nil, customErr.WrapError(ctx, errConstant.ErrSQL)
 	}
 
 	return &user, nil
@@ -54,40 +77,65 @@ func (ur *UserRepository) FindByEmail(ctx context.Context, email string) (*model
 func (ur *UserRepository) FindByUUID(ctx context.Context, uuid string) (*models.User, error) {
 	var user models.User
 
-	err := ur.db.
-		WithContext(ctx).
-		Model(&models.User{}).
-		Preload("Role").
-		Where("uuid = ?", uuid).
-		First(&user).
-		Error
+	err := ur.resolver.Read(ctx, findByUUID(ctx, uuid, &user))
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errConstant.ErrUserNotFound
 		}
 
-		return nil, customErr.WrapError(errConstant.ErrSQL)
+		return nil, customErr.WrapError(ctx, errConstant.ErrSQL)
 	}
 
 	return &user, nil
 }
 
+// FindByUUIDFromPrimary skips the replicas, for reads whose version guards a
+// write and must not be stale.
+func (ur *UserRepository) FindByUUIDFromPrimary(ctx context.Context, uuid string) (*models.User, error) {
+	var user models.User
+
+	err := findByUUID(ctx, uuid, &user)(ur.resolver.Primary())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errConstant.ErrUserNotFound
+		}
+
+		return nil, customErr.WrapError(ctx, errConstant.ErrSQL)
+	}
+
+	return &user, nil
+}
+
+func findByUUID(ctx context.Context, uuid string, user *models.User) func(*gorm.DB) error {
+	return func(db *gorm.DB) error {
+		return db.
+			WithContext(ctx).
+			Model(&models.User{}).
+			Preload("Role").
+			Where("uuid = ?", uuid).
+			First(user).
+			Error
+	}
+}
+
 func (ur *UserRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
 	var user models.User
 
-	err := ur.db.
-		WithContext(ctx).
-		Model(&models.User{}).
-		Preload("Role").
-		Where("username = ?", username).
-		First(&user).
-		Error
+	err := ur.resolver.Read(ctx, func(db *gorm.DB) error {
+		return db.
+			WithContext(ctx).
+			Model(&models.User{}).
+			Preload("Role").
+			Where("LOWER(username) = LOWER(?)", username).
+			First(&user).
+			Error
+	})
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errConstant.ErrUserNotFound
 		}
 
-		return nil, customErr.WrapError(errConstant.ErrSQL)
+		return nil, customErr.WrapError(ctx, errConstant.ErrSQL)
 	}
 
 	return &user, nil
@@ -110,31 +158,252 @@ func (ur *UserRepository) Register(ctx context.Context, req *dto.RegisterRequest
 		Create(&user).
 		Error
 	if err != nil {
-		return nil, customErr.WrapError(errConstant.ErrSQL)
+		return nil, customErr.WrapError(ctx, errConstant.ErrSQL)
 	}
 
 	return &user, err
 }
 
-func (ur *UserRepository) Update(ctx context.Context, req *dto.UpdateRequest, userUuid string) (*models.User, error) {
-	user := models.User{
-		UUID:        uuid.MustParse(userUuid),
-		Name:        req.Name,
-		Username:    req.Username,
-		Email:       req.Email,
-		Password:    *req.Password,
-		PhoneNumber: req.PhoneNumber,
-		RoleID:      req.RoleID,
+func (ur *UserRepository) Update(ctx context.Context, req *dto.UpdateRequest, userUuid string, version uint) (*models.User, error) {
+	fields := map[string]any{
+		"name":         req.Name,
+		"username":     req.Username,
+		"email":        req.Email,
+		"phone_number": req.PhoneNumber,
+	}
+
+	return ur.UpdateFields(ctx, userUuid, version, fields)
+}
+
+// UpdateRoles applies every assignment in one transaction. Users whose role
+// actually changes get their token version bumped so existing tokens stop
+// working. The transaction is rolled back if no administrator would remain.
+func (ur *UserRepository) UpdateRoles(ctx context.Context, assignments []dto.RoleAssignment) ([]RoleUpdate, error) {
+	updates := make([]RoleUpdate, 0, len(assignments))
+
+	err := ur.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var adminIDs []uint
+		err := tx.
+			Model(&models.User{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Joins("JOIN roles ON roles.id = users.role_id").
+			Where("roles.code = ?", constants.AdminCode).
+			Pluck("users.id", &adminIDs).
+			Error
+		if err != nil {
+			return customErr.WrapError(ctx, errConstant.ErrSQL)
+		}
+
+		for _, assignment := range assignments {
+			var user models.User
+			err = tx.
+				Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("uuid = ?", assignment.UUID).
+				First(&user).
+				Error
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return errConstant.ErrUserNotFound
+				}
+
+				return customErr.WrapError(ctx, errConstant.ErrSQL)
+			}
+
+			changed := user.RoleID != assignment.RoleID
+			if changed {
+				err = tx.
+					Model(&user).
+					Updates(map[string]any{
+						"role_id":       assignment.RoleID,
+						"token_version": gorm.Expr("token_version + 1"),
+						"version":       gorm.Expr("version + 1"),
+					}).
+					Error
+				if err != nil {
+					return customErr.WrapError(ctx, errConstant.ErrSQL)
+				}
+			}
+
+			updates = append(updates, RoleUpdate{User: user, Changed: changed})
+		}
+
+		var remaining int64
+		err = tx.
+			Model(&models.User{}).
+			Joins("JOIN roles ON roles.id = users.role_id").
+			Where("roles.code = ?", constants.AdminCode).
+			Count(&remaining).
+			Error
+		if err != nil {
+			return customErr.WrapError(ctx, errConstant.ErrSQL)
+		}
+
+		if len(adminIDs) > 0 && remaining == 0 {
+			return errConstant.ErrLastAdmin
+		}
+
+		for i := range updates {
+			err = tx.Preload("Role").First(&updates[i].User, updates[i].User.ID).Error
+			if err != nil {
+				return customErr.WrapError(ctx, errConstant.ErrSQL)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updates, nil
+}
+
+// UpdateFields only writes the given columns, so a partial update never
+// touches values the caller did not send. The write is conditional on the
+// version the caller read, which makes the If-Match check atomic.
+func (ur *UserRepository) UpdateFields(ctx context.Context, userUuid string, version uint, fields map[string]any) (*models.User, error) {
+	fields["version"] = gorm.Expr("version + 1")
+
+	result := ur.db.
+		WithContext(ctx).
+		Model(&models.User{}).
+		Where("uuid = ? AND version = ?", userUuid, version).
+		Updates(fields)
+	if result.Error != nil {
+		return nil, customErr.WrapError(ctx, errConstant.ErrSQL)
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, errConstant.ErrVersionMismatch
 	}
 
+	return ur.FindByUUID(ctx, userUuid)
+}
+
+func (ur *UserRepository) UpdateAvatar(ctx context.Context, userUuid string, avatarKey string) (*models.User, error) {
 	err := ur.db.
 		WithContext(ctx).
+		Model(&models.User{}).
 		Where("uuid = ?", userUuid).
-		Updates(&user).
+		Updates(map[string]any{
+			"avatar_key": avatarKey,
+			"version":    gorm.Expr("version + 1"),
+		}).
 		Error
 	if err != nil {
-		return nil, customErr.WrapError(errConstant.ErrSQL)
+		return nil, customErr.WrapError(ctx, errConstant.ErrSQL)
 	}
 
-	return &user, err
+	return ur.FindByUUID(ctx, userUuid)
+}
+
+// UpdatePassword stores the new hash, moves the old one into the password
+// history and bumps the token version so every issued token is revoked.
+func (ur *UserRepository) UpdatePassword(ctx context.Context, userID uint, password string) (*models.User, error) {
+	var user models.User
+
+	err := ur.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", userID).
+			First(&user).
+			Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errConstant.ErrUserNotFound
+			}
+
+			return customErr.WrapError(ctx, errConstant.ErrSQL)
+		}
+
+		err = tx.Create(&models.PasswordHistory{
+			UserID:   user.ID,
+			Password: user.Password,
+		}).Error
+		if err != nil {
+			return customErr.WrapError(ctx, errConstant.ErrSQL)
+		}
+
+		err = tx.
+			Where("user_id = ?", user.ID).
+			Where(
+				"id NOT IN (?)",
+				tx.
+					Model(&models.PasswordHistory{}).
+					Select("id").
+					Where("user_id = ?", user.ID).
+					Order("id DESC").
+					Limit(constants.PasswordHistorySize),
+			).
+			Delete(&models.PasswordHistory{}).
+			Error
+		if err != nil {
+			return customErr.WrapError(ctx, errConstant.ErrSQL)
+		}
+
+		err = tx.
+			Model(&user).
+			Updates(map[string]any{
+				"password":      password,
+				"token_version": gorm.Expr("token_version + 1"),
+			}).
+			Error
+		if err != nil {
+			return customErr.WrapError(ctx, errConstant.ErrSQL)
+		}
+
+		err = tx.Preload("Role").First(&user, user.ID).Error
+		if err != nil {
+			return customErr.WrapError(ctx, errConstant.ErrSQL)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (ur *UserRepository) FindExistingUsernames(ctx context.Context, usernames []string) ([]string, error) {
+	var existing []string
+
+	err := ur.resolver.Read(ctx, func(db *gorm.DB) error {
+		return db.
+			WithContext(ctx).
+			Model(&models.User{}).
+			Where("LOWER(username) IN ?", usernames).
+			Pluck("LOWER(username)", &existing).
+			Error
+	})
+	if err != nil {
+		return nil, customErr.WrapError(ctx, errConstant.ErrSQL)
+	}
+
+	return existing, nil
+}
+
+// FindTokenVersion reads only the token version, since it is checked on
+// every authenticated request. It reads the primary, a lagging replica would
+// accept tokens that were just revoked.
+func (ur *UserRepository) FindTokenVersion(ctx context.Context, uuid string) (uint, error) {
+	var versions []uint
+
+	err := ur.db.
+		WithContext(ctx).
+		Model(&models.User{}).
+		Where("uuid = ?", uuid).
+		Limit(1).
+		Pluck("token_version", &versions).
+		Error
+	if err != nil {
+		return 0, customErr.WrapError(ctx, errConstant.ErrSQL)
+	}
+
+	if len(versions) == 0 {
+		return 0, errConstant.ErrUserNotFound
+	}
+
+	return versions[0], nil
 }
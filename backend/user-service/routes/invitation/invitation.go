@@ -0,0 +1,42 @@
+package invitation
+
+import (
+	"user-service/constants"
+	"user-service/controllers"
+	"user-service/middlewares"
+	"user-service/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InvitationRoute struct {
+	controller controllers.IControllerRegistry
+	service    services.IServiceRegistry
+	group      *gin.RouterGroup
+}
+
+type IInvitationRoute interface {
+	Run()
+}
+
+func NewInvitationRoute(
+	controller controllers.IControllerRegistry,
+	service services.IServiceRegistry,
+	group *gin.RouterGroup,
+) IInvitationRoute {
+	return &InvitationRoute{
+		controller: controller,
+		service:    service,
+		group:      group,
+	}
+}
+
+func (ir *InvitationRoute) Run() {
+	group := ir.group.Group("/invitations")
+	group.POST("/accept", ir.controller.GetInvitationController().Accept)
+
+	admin := group.Group("", middlewares.Authenticate(ir.service), middlewares.CheckRole(constants.AdminCode))
+	admin.GET("", ir.controller.GetInvitationController().GetPending)
+	admin.POST("", ir.controller.GetInvitationController().Create)
+	admin.DELETE("/:uuid", ir.controller.GetInvitationController().Revoke)
+}
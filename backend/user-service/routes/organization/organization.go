@@ -0,0 +1,47 @@
+package organization
+
+import (
+	"user-service/constants"
+	"user-service/controllers"
+	"user-service/middlewares"
+	"user-service/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OrganizationRoute struct {
+	controller controllers.IControllerRegistry
+	service    services.IServiceRegistry
+	group      *gin.RouterGroup
+}
+
+type IOrganizationRoute interface {
+	Run()
+}
+
+func NewOrganizationRoute(
+	controller controllers.IControllerRegistry,
+	service services.IServiceRegistry,
+	group *gin.RouterGroup,
+) IOrganizationRoute {
+	return &OrganizationRoute{
+		controller: controller,
+		service:    service,
+		group:      group,
+	}
+}
+
+func (or *OrganizationRoute) Run() {
+	group := or.group.Group("/organizations", middlewares.Authenticate(or.service))
+	group.GET("", or.controller.GetOrganizationController().GetMine)
+	group.POST("", middlewares.CheckRole(constants.AdminCode), or.controller.GetOrganizationController().Create)
+
+	members := group.Group(
+		"/members",
+		middlewares.ResolveOrganization(or.service),
+		middlewares.CheckOrganizationRole(constants.AdminCode, constants.OwnerCode),
+	)
+	members.GET("", or.controller.GetOrganizationController().GetMembers)
+	members.PUT("", or.controller.GetOrganizationController().UpsertMember)
+	members.DELETE("/:uuid", or.controller.GetOrganizationController().RemoveMember)
+}
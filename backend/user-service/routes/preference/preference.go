@@ -0,0 +1,40 @@
+package preference
+
+import (
+	"user-service/controllers"
+	"user-service/middlewares"
+	"user-service/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PreferenceRoute struct {
+	controller controllers.IControllerRegistry
+	service    services.IServiceRegistry
+	group      *gin.RouterGroup
+}
+
+type IPreferenceRoute interface {
+	Run()
+}
+
+func NewPreferenceRoute(
+	controller controllers.IControllerRegistry,
+	service services.IServiceRegistry,
+	group *gin.RouterGroup,
+) IPreferenceRoute {
+	return &PreferenceRoute{
+		controller: controller,
+		service:    service,
+		group:      group,
+	}
+}
+
+func (pr *PreferenceRoute) Run() {
+	group := pr.group.Group("/auth/user/preferences", middlewares.Authenticate(pr.service))
+	group.GET("", pr.controller.GetPreferenceController().Get)
+	group.PATCH("", pr.controller.GetPreferenceController().Patch)
+
+	internal := pr.group.Group("/internal/users", middlewares.AuthenticateService())
+	internal.GET("/:uuid/preferences", pr.controller.GetPreferenceController().GetByUserUUID)
+}
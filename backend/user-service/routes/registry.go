@@ -2,13 +2,18 @@ package routes
 
 import (
 	"user-service/controllers"
+	"user-service/routes/invitation"
+	"user-service/routes/organization"
+	"user-service/routes/preference"
 	"user-service/routes/user"
+	"user-service/services"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Registry struct {
 	controller controllers.IControllerRegistry
+	service    services.IServiceRegistry
 	group      *gin.RouterGroup
 }
 
@@ -16,17 +21,37 @@ type IRouteRegistry interface {
 	Serve()
 }
 
-func NewRouteRegistry(controller controllers.IControllerRegistry, group *gin.RouterGroup) IRouteRegistry {
+func NewRouteRegistry(
+	controller controllers.IControllerRegistry,
+	service services.IServiceRegistry,
+	group *gin.RouterGroup,
+) IRouteRegistry {
 	return &Registry{
 		controller: controller,
+		service:    service,
 		group:      group,
 	}
 }
 
 func (r *Registry) userRoute() user.IUserRoute {
-	return user.NewUserRoute(r.controller, r.group)
+	return user.NewUserRoute(r.controller, r.service, r.group)
+}
+
+func (r *Registry) invitationRoute() invitation.IInvitationRoute {
+	return invitation.NewInvitationRoute(r.controller, r.service, r.group)
+}
+
+func (r *Registry) organizationRoute() organization.IOrganizationRoute {
+	return organization.NewOrganizationRoute(r.controller, r.service, r.group)
+}
+
+func (r *Registry) preferenceRoute() preference.IPreferenceRoute {
+	return preference.NewPreferenceRoute(r.controller, r.service, r.group)
 }
 
 func (r *Registry) Serve() {
 	r.userRoute().Run()
+	r.invitationRoute().Run()
+	r.organizationRoute().Run()
+	r.preferenceRoute().Run()
 }
@@ -1,14 +1,21 @@
 package user
 
 import (
+	"time"
+	"user-service/config"
+	"user-service/constants"
 	"user-service/controllers"
 	"user-service/middlewares"
+	"user-service/services"
 
+	"github.com/didip/tollbooth"
+	"github.com/didip/tollbooth/limiter"
 	"github.com/gin-gonic/gin"
 )
 
 type UserRoute struct {
 	controller controllers.IControllerRegistry
+	service    services.IServiceRegistry
 	group      *gin.RouterGroup
 }
 
@@ -16,18 +23,85 @@ type IUserRoute interface {
 	Run()
 }
 
-func NewUserRoute(controller controllers.IControllerRegistry, group *gin.RouterGroup) IUserRoute {
+func NewUserRoute(
+	controller controllers.IControllerRegistry,
+	service services.IServiceRegistry,
+	group *gin.RouterGroup,
+) IUserRoute {
 	return &UserRoute{
 		controller: controller,
+		service:    service,
 		group:      group,
 	}
 }
 
+// availabilityLimiter is stricter than the global limiter because the
+// endpoint can otherwise be used to enumerate registered accounts. It allows
+// AvailabilityMaxRequest requests per minute, all of which may be spent at
+// once.
+func (ur *UserRoute) availabilityLimiter() *limiter.Limiter {
+	lmt := tollbooth.NewLimiter(0, &limiter.ExpirableOptions{
+		DefaultExpirationTTL: time.Minute,
+	})
+	setPerMinute(lmt, config.Get().AvailabilityMaxRequest)
+
+	config.Subscribe(func(prev, next *config.AppConfig) {
+		if prev.AvailabilityMaxRequest != next.AvailabilityMaxRequest {
+			setPerMinute(lmt, next.AvailabilityMaxRequest)
+		}
+	})
+
+	return lmt
+}
+
+// setPerMinute converts a per minute budget into tollbooth's per second rate.
+func setPerMinute(lmt *limiter.Limiter, n float64) {
+	lmt.SetMax(n / 60)
+	lmt.SetBurst(int(max(1, n)))
+}
+
 func (ur *UserRoute) Run() {
 	group := ur.group.Group("/auth")
-	group.GET("/user", middlewares.Authenticate(), ur.controller.GetUserController().GetUserLogin)
-	group.GET("/:uuid", middlewares.Authenticate(), ur.controller.GetUserController().GetUserByUUID)
+	group.GET("/user", middlewares.Authenticate(ur.service), ur.controller.GetUserController().GetUserLogin)
+	group.GET("/:uuid", middlewares.Authenticate(ur.service), ur.controller.GetUserController().GetUserByUUID)
+	group.PUT("/user/avatar", middlewares.Authenticate(ur.service), ur.controller.GetUserController().UpdateAvatar)
+	group.POST(
+		"/user/password",
+		middlewares.Authenticate(ur.service),
+		middlewares.BlockImpersonation(),
+		ur.controller.GetUserController().ChangePassword,
+	)
 	group.POST("/login", ur.controller.GetUserController().Login)
 	group.POST("/register", ur.controller.GetUserController().Register)
-	group.PUT("/:uuid", middlewares.Authenticate(), ur.controller.GetUserController().Update)
+	group.POST("/email/confirm", ur.controller.GetUserController().ConfirmEmail)
+	group.POST("/email/revert", ur.controller.GetUserController().RevertEmail)
+	group.GET("/availability", middlewares.RateLimiter(ur.availabilityLimiter()), ur.controller.GetUserController().CheckAvailability)
+	group.PUT(
+		"/:uuid",
+		middlewares.Authenticate(ur.service),
+		middlewares.BlockImpersonation(),
+		ur.controller.GetUserController().Update,
+	)
+	group.PATCH(
+		"/:uuid",
+		middlewares.Authenticate(ur.service),
+		middlewares.BlockImpersonation(),
+		ur.controller.GetUserController().Patch,
+	)
+	group.POST(
+		"/impersonate/:uuid",
+		middlewares.Authenticate(ur.service),
+		middlewares.CheckRole(constants.AdminCode),
+		middlewares.BlockImpersonation(),
+		ur.controller.GetUserController().Impersonate,
+	)
+
+	users := ur.group.Group(
+		"/users",
+		middlewares.Authenticate(ur.service),
+		middlewares.CheckRole(constants.AdminCode),
+		middlewares.BlockImpersonation(),
+	)
+	users.PUT("/roles", ur.controller.GetUserController().BulkUpdateRole)
+	users.PUT("/:uuid/role", ur.controller.GetUserController().UpdateRole)
 }
@@ -0,0 +1,47 @@
+package audit
+
+import (
+	"context"
+	"user-service/common/logger"
+	"user-service/domain/dto"
+	"user-service/repositories"
+
+	"github.com/sirupsen/logrus"
+)
+
+type AuditService struct {
+	repository repositories.IRepositoryRegistry
+}
+
+type IAuditService interface {
+	Record(context.Context, *dto.AuditRequest) error
+}
+
+func NewAuditService(repository repositories.IRepositoryRegistry) IAuditService {
+	return &AuditService{
+		repository: repository,
+	}
+}
+
+func (as *AuditService) Record(ctx context.Context, req *dto.AuditRequest) error {
+	fields := logrus.Fields{
+		"actor":  req.ActorUUID,
+		"action": req.Action,
+	}
+	if req.SubjectUUID != nil {
+		fields["subject"] = *req.SubjectUUID
+	}
+	if req.Path != "" {
+		fields["method"] = req.Method
+		fields["path"] = req.Path
+		fields["status"] = req.StatusCode
+	}
+	logger.FromContext(ctx).WithFields(fields).Info("audit event")
+
+	_, err := as.repository.GetAudit().Create(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
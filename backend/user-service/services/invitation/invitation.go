@@ -0,0 +1,266 @@
+package invitation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"user-service/clients"
+	"user-service/common/hashing"
+	"user-service/common/logger"
+	"user-service/common/metrics"
+	"user-service/common/util"
+	"user-service/config"
+	"user-service/constants"
+	errConstant "user-service/constants/custom-error"
+	"user-service/domain/dto"
+	"user-service/domain/models"
+	"user-service/repositories"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const defaultInvitationExpirationTime = 72
+
+type InvitationService struct {
+	repository repositories.IRepositoryRegistry
+	client     clients.IClientRegistry
+}
+
+type IInvitationService interface {
+	Create(context.Context, *dto.InvitationRequest) (*dto.InvitationResponse, error)
+	GetPending(context.Context) ([]dto.InvitationResponse, error)
+	Revoke(context.Context, string) error
+	Accept(context.Context, *dto.AcceptInvitationRequest) (*dto.UserResponse, error)
+}
+
+func NewInvitationService(repository repositories.IRepositoryRegistry, client clients.IClientRegistry) IInvitationService {
+	return &InvitationService{
+		repository: repository,
+		client:     client,
+	}
+}
+
+func (is *InvitationService) Create(ctx context.Context, req *dto.InvitationRequest) (*dto.InvitationResponse, error) {
+	admin := ctx.Value(constants.UserLogin).(*dto.UserResponse)
+	req.Email = util.NormalizeEmail(req.Email)
+
+	role, err := is.repository.GetRole().FindByID(ctx, req.RoleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if role.Code == constants.CustomerCode {
+		return nil, errConstant.ErrInvalidStaffRole
+	}
+
+	_, err = is.repository.GetUser().FindByEmail(ctx, req.Email)
+	if err == nil {
+		return nil, errConstant.ErrEmailExist
+	}
+
+	if !errors.Is(err, errConstant.ErrUserNotFound) {
+		return nil, err
+	}
+
+	reserved, err := is.repository.GetEmailChange().IsEmailReserved(ctx, req.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if reserved {
+		return nil, errConstant.ErrEmailExist
+	}
+
+	_, err = is.repository.GetInvitation().FindPendingByEmail(ctx, req.Email)
+	if err == nil {
+		return nil, errConstant.ErrInvitationExist
+	}
+
+	if !errors.Is(err, errConstant.ErrInvitationNotFound) {
+		return nil, err
+	}
+
+	token, err := util.GenerateToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	expirationTime := config.Get().InvitationExpirationTime
+	if expirationTime <= 0 {
+		expirationTime = defaultInvitationExpirationTime
+	}
+
+	invitation, err := is.repository.GetInvitation().Create(ctx, &models.Invitation{
+		UUID:      uuid.New(),
+		Email:     req.Email,
+		RoleID:    role.ID,
+		TokenHash: util.HashToken(token),
+		InvitedBy: admin.UUID,
+		ExpiredAt: time.Now().Add(time.Duration(expirationTime) * time.Hour),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = is.repository.GetAudit().Create(ctx, &dto.AuditRequest{
+		ActorUUID:   admin.UUID,
+		Action:      constants.AuditInvitationCreate,
+		Description: fmt.Sprintf("invited %s as %s", invitation.Email, role.Code),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = is.client.GetNotification().SendEmail(ctx, &dto.EmailRequest{
+		To:      invitation.Email,
+		Subject: fmt.Sprintf("You are invited to join %s", config.Get().AppName),
+		Body: fmt.Sprintf(
+			"You have been invited as %s. Accept the invitation before %s: %s/invitations/accept?token=%s",
+			role.Name,
+			invitation.ExpiredAt.Format(time.RFC1123),
+			config.Get().FrontendURL,
+			token,
+		),
+	})
+	if err != nil {
+		logger.FromContext(ctx).Errorf("failed to send invitation email: %v", err)
+	}
+
+	invitation.Role = *role
+	response := is.toResponse(invitation)
+
+	return &response, nil
+}
+
+func (is *InvitationService) GetPending(ctx context.Context) ([]dto.InvitationResponse, error) {
+	invitations, err := is.repository.GetInvitation().FindPending(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.InvitationResponse, 0, len(invitations))
+	for _, invitation := range invitations {
+		response = append(response, is.toResponse(&invitation))
+	}
+
+	return response, nil
+}
+
+func (is *InvitationService) Revoke(ctx context.Context, uuid string) error {
+	admin := ctx.Value(constants.UserLogin).(*dto.UserResponse)
+
+	invitation, err := is.repository.GetInvitation().FindByUUID(ctx, uuid)
+	if err != nil {
+		return err
+	}
+
+	err = is.repository.GetInvitation().Revoke(ctx, uuid)
+	if err != nil {
+		return err
+	}
+
+	_, err = is.repository.GetAudit().Create(ctx, &dto.AuditRequest{
+		ActorUUID:   admin.UUID,
+		Action:      constants.AuditInvitationRevoke,
+		Description: fmt.Sprintf("revoked invitation for %s", invitation.Email),
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (is *InvitationService) Accept(ctx context.Context, req *dto.AcceptInvitationRequest) (*dto.UserResponse, error) {
+	invitation, err := is.repository.GetInvitation().FindPendingByTokenHash(ctx, util.HashToken(req.Token))
+	if err != nil {
+		return nil, err
+	}
+
+	if time.Now().After(invitation.ExpiredAt) {
+		return nil, errConstant.ErrInvitationExpired
+	}
+
+	req.Username = util.NormalizeUsername(req.Username)
+
+	_, err = is.repository.GetUser().FindByUsername(ctx, req.Username)
+	if err == nil {
+		return nil, errConstant.ErrUsernameExist
+	}
+
+	if !errors.Is(err, errConstant.ErrUserNotFound) {
+		return nil, err
+	}
+
+	_, err = is.repository.GetUser().FindByEmail(ctx, invitation.Email)
+	if err == nil {
+		return nil, errConstant.ErrEmailExist
+	}
+
+	if !errors.Is(err, errConstant.ErrUserNotFound) {
+		return nil, err
+	}
+
+	if req.Password != req.ConfirmPassword {
+		return nil, errConstant.ErrPasswordDoesNotMatch
+	}
+
+	err = util.CheckPasswordPolicy(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	hashedPass, err := hashing.Generate(ctx, []byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := is.repository.GetInvitation().Accept(ctx, invitation, &models.User{
+		UUID:        uuid.New(),
+		Name:        req.Name,
+		Username:    req.Username,
+		Email:       invitation.Email,
+		Password:    string(hashedPass),
+		PhoneNumber: req.PhoneNumber,
+		RoleID:      invitation.RoleID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = is.repository.GetAudit().Create(ctx, &dto.AuditRequest{
+		ActorUUID:   user.UUID,
+		SubjectUUID: &user.UUID,
+		Action:      constants.AuditInvitationAccept,
+		Description: fmt.Sprintf("accepted invitation from %s as %s", invitation.InvitedBy, invitation.Role.Code),
+	})
+	if err != nil {
+		logger.FromContext(ctx).Errorf("failed to record invitation audit: %v", err)
+	}
+
+	response := &dto.UserResponse{
+		UUID:        user.UUID,
+		Name:        user.Name,
+		Username:    user.Username,
+		Email:       user.Email,
+		PhoneNumber: user.PhoneNumber,
+		Role:        invitation.Role.Code,
+	}
+
+	metrics.Registrations.WithLabelValues(metrics.RegistrationInvitation).Inc()
+
+	return response, nil
+}
+
+func (is *InvitationService) toResponse(invitation *models.Invitation) dto.InvitationResponse {
+	return dto.InvitationResponse{
+		UUID:      invitation.UUID,
+		Email:     invitation.Email,
+		Role:      invitation.Role.Code,
+		InvitedBy: invitation.InvitedBy,
+		ExpiredAt: invitation.ExpiredAt,
+		CreatedAt: invitation.CreatedAt,
+	}
+}
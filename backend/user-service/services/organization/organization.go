@@ -0,0 +1,268 @@
+package organization
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"user-service/common/logger"
+	"user-service/constants"
+	errConstant "user-service/constants/custom-error"
+	"user-service/domain/dto"
+	"user-service/domain/models"
+	"user-service/repositories"
+)
+
+type OrganizationService struct {
+	repository repositories.IRepositoryRegistry
+}
+
+type IOrganizationService interface {
+	Create(context.Context, *dto.OrganizationRequest) (*dto.OrganizationResponse, error)
+	GetMine(context.Context) ([]dto.OrganizationResponse, error)
+	Resolve(context.Context, string) (*dto.OrganizationContext, error)
+	GetMembers(context.Context) ([]dto.MembershipResponse, error)
+	UpsertMember(context.Context, *dto.MembershipRequest) (*dto.MembershipResponse, error)
+	RemoveMember(context.Context, string) error
+}
+
+func NewOrganizationService(repository repositories.IRepositoryRegistry) IOrganizationService {
+	return &OrganizationService{
+		repository: repository,
+	}
+}
+
+func (os *OrganizationService) Create(ctx context.Context, req *dto.OrganizationRequest) (*dto.OrganizationResponse, error) {
+	organization, err := os.repository.GetOrganization().Create(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.OrganizationResponse{
+		UUID:      organization.UUID,
+		Name:      organization.Name,
+		Address:   organization.Address,
+		CreatedAt: organization.CreatedAt,
+	}
+
+	return response, nil
+}
+
+func (os *OrganizationService) GetMine(ctx context.Context) ([]dto.OrganizationResponse, error) {
+	userLogin := ctx.Value(constants.UserLogin).(*dto.UserResponse)
+
+	if userLogin.Role == constants.AdminCode {
+		organizations, err := os.repository.GetOrganization().FindAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		response := make([]dto.OrganizationResponse, 0, len(organizations))
+		for _, organization := range organizations {
+			response = append(response, dto.OrganizationResponse{
+				UUID:      organization.UUID,
+				Name:      organization.Name,
+				Address:   organization.Address,
+				Role:      constants.AdminCode,
+				CreatedAt: organization.CreatedAt,
+			})
+		}
+
+		return response, nil
+	}
+
+	memberships, err := os.repository.GetMembership().FindByUser(ctx, userLogin.UUID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.OrganizationResponse, 0, len(memberships))
+	for _, membership := range memberships {
+		response = append(response, dto.OrganizationResponse{
+			UUID:      membership.Organization.UUID,
+			Name:      membership.Organization.Name,
+			Address:   membership.Organization.Address,
+			Role:      membership.Role.Code,
+			CreatedAt: membership.Organization.CreatedAt,
+		})
+	}
+
+	return response, nil
+}
+
+// Resolve loads the organization and the role the logged in user holds
+// there. Administrators act as ADMIN everywhere and users without a
+// membership are customers of every venue.
+func (os *OrganizationService) Resolve(ctx context.Context, uuid string) (*dto.OrganizationContext, error) {
+	userLogin := ctx.Value(constants.UserLogin).(*dto.UserResponse)
+
+	organization, err := os.repository.GetOrganization().FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &dto.OrganizationContext{
+		ID:   organization.ID,
+		UUID: organization.UUID,
+		Name: organization.Name,
+		Role: constants.CustomerCode,
+	}
+
+	if userLogin.Role == constants.AdminCode {
+		data.Role = constants.AdminCode
+		return data, nil
+	}
+
+	membership, err := os.repository.GetMembership().FindByUserAndOrganization(ctx, userLogin.UUID.String(), organization.ID)
+	if err != nil {
+		if errors.Is(err, errConstant.ErrMembershipNotFound) {
+			return data, nil
+		}
+
+		return nil, err
+	}
+
+	data.Role = membership.Role.Code
+
+	return data, nil
+}
+
+func (os *OrganizationService) GetMembers(ctx context.Context) ([]dto.MembershipResponse, error) {
+	memberships, err := os.repository.GetMembership().FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.MembershipResponse, 0, len(memberships))
+	for _, membership := range memberships {
+		response = append(response, dto.MembershipResponse{
+			User: dto.UserResponse{
+				UUID:        membership.User.UUID,
+				Name:        membership.User.Name,
+				Username:    membership.User.Username,
+				Email:       membership.User.Email,
+				PhoneNumber: membership.User.PhoneNumber,
+			},
+			Role:      membership.Role.Code,
+			CreatedAt: membership.CreatedAt,
+		})
+	}
+
+	return response, nil
+}
+
+func (os *OrganizationService) UpsertMember(ctx context.Context, req *dto.MembershipRequest) (*dto.MembershipResponse, error) {
+	userLogin := ctx.Value(constants.UserLogin).(*dto.UserResponse)
+	organization := ctx.Value(constants.Organization).(*dto.OrganizationContext)
+
+	role, err := os.repository.GetRole().FindByID(ctx, req.RoleID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch role.Code {
+	case constants.OwnerCode, constants.CashierCode, constants.CustomerCode:
+	default:
+		return nil, errConstant.ErrInvalidVenueRole
+	}
+
+	// Owners run their venue but cannot hand the venue to someone else.
+	if role.Code == constants.OwnerCode && organization.Role != constants.AdminCode {
+		return nil, errConstant.ErrOwnerGrantForbidden
+	}
+
+	err = os.authorizeOwnerChange(ctx, organization, req.UserUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := os.repository.GetUser().FindByUUID(ctx, req.UserUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	membership, err := os.repository.GetMembership().Upsert(ctx, &models.Membership{
+		UserID: user.ID,
+		RoleID: role.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = os.repository.GetAudit().Create(ctx, &dto.AuditRequest{
+		ActorUUID:   userLogin.UUID,
+		SubjectUUID: &user.UUID,
+		Action:      constants.AuditMembershipUpdate,
+		Description: fmt.Sprintf("role set to %s at %s", role.Code, organization.UUID),
+	})
+	if err != nil {
+		logger.FromContext(ctx).Errorf("failed to record membership audit: %v", err)
+	}
+
+	response := &dto.MembershipResponse{
+		User: dto.UserResponse{
+			UUID:        user.UUID,
+			Name:        user.Name,
+			Username:    user.Username,
+			Email:       user.Email,
+			PhoneNumber: user.PhoneNumber,
+		},
+		Role:      role.Code,
+		CreatedAt: membership.CreatedAt,
+	}
+
+	return response, nil
+}
+
+func (os *OrganizationService) RemoveMember(ctx context.Context, uuid string) error {
+	userLogin := ctx.Value(constants.UserLogin).(*dto.UserResponse)
+	organization := ctx.Value(constants.Organization).(*dto.OrganizationContext)
+
+	err := os.authorizeOwnerChange(ctx, organization, uuid)
+	if err != nil {
+		return err
+	}
+
+	user, err := os.repository.GetUser().FindByUUID(ctx, uuid)
+	if err != nil {
+		return err
+	}
+
+	err = os.repository.GetMembership().Delete(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = os.repository.GetAudit().Create(ctx, &dto.AuditRequest{
+		ActorUUID:   userLogin.UUID,
+		SubjectUUID: &user.UUID,
+		Action:      constants.AuditMembershipRemove,
+		Description: fmt.Sprintf("removed from %s", organization.UUID),
+	})
+	if err != nil {
+		logger.FromContext(ctx).Errorf("failed to record membership audit: %v", err)
+	}
+
+	return nil
+}
+
+// authorizeOwnerChange keeps owners from demoting or removing each other, so
+// an owner cannot take the venue from its co-owners or leave it without one.
+func (os *OrganizationService) authorizeOwnerChange(ctx context.Context, organization *dto.OrganizationContext, userUUID string) error {
+	if organization.Role == constants.AdminCode {
+		return nil
+	}
+
+	membership, err := os.repository.GetMembership().FindByUserAndOrganization(ctx, userUUID, organization.ID)
+	if errors.Is(err, errConstant.ErrMembershipNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if membership.Role.Code == constants.OwnerCode {
+		return errConstant.ErrOwnerChangeForbidden
+	}
+
+	return nil
+}
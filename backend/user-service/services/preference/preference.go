@@ -0,0 +1,180 @@
+package preference
+
+import (
+	"context"
+	"errors"
+	"os"
+	"user-service/constants"
+	errConstant "user-service/constants/custom-error"
+	"user-service/domain/dto"
+	"user-service/domain/models"
+	"user-service/repositories"
+)
+
+type PreferenceService struct {
+	repository repositories.IRepositoryRegistry
+}
+
+type IPreferenceService interface {
+	Get(context.Context) (*dto.PreferencesResponse, error)
+	GetByUserUUID(context.Context, string) (*dto.PreferencesResponse, error)
+	Patch(context.Context, *dto.PreferencesPatchRequest) (*dto.PreferencesResponse, error)
+}
+
+func NewPreferenceService(repository repositories.IRepositoryRegistry) IPreferenceService {
+	return &PreferenceService{
+		repository: repository,
+	}
+}
+
+func (ps *PreferenceService) Get(ctx context.Context) (*dto.PreferencesResponse, error) {
+	userLogin := ctx.Value(constants.UserLogin).(*dto.UserResponse)
+
+	return ps.GetByUserUUID(ctx, userLogin.UUID.String())
+}
+
+func (ps *PreferenceService) GetByUserUUID(ctx context.Context, uuid string) (*dto.PreferencesResponse, error) {
+	user, err := ps.repository.GetUser().FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences, err := ps.load(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toResponse(preferences), nil
+}
+
+func (ps *PreferenceService) Patch(ctx context.Context, req *dto.PreferencesPatchRequest) (*dto.PreferencesResponse, error) {
+	userLogin := ctx.Value(constants.UserLogin).(*dto.UserResponse)
+
+	user, err := ps.repository.GetUser().FindByUUID(ctx, userLogin.UUID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	preferences, err := ps.load(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Language != nil {
+		preferences.Language = *req.Language
+	}
+
+	if req.Timezone != nil {
+		preferences.Timezone = *req.Timezone
+	}
+
+	if req.PreferredPosition != nil {
+		preferences.PreferredPosition = *req.PreferredPosition
+	}
+
+	for event, patch := range req.Notifications {
+		channels := preferences.Notifications[event]
+		if patch.Email != nil {
+			channels.Email = *patch.Email
+		}
+
+		if patch.SMS != nil {
+			channels.SMS = *patch.SMS
+		}
+
+		if patch.Push != nil {
+			channels.Push = *patch.Push
+		}
+
+		preferences.Notifications[event] = channels
+	}
+
+	preference, err := ps.repository.GetPreference().Upsert(ctx, user.ID, preferences)
+	if err != nil {
+		return nil, err
+	}
+
+	return toResponse(preference.Preferences), nil
+}
+
+// load returns the stored preferences layered over the defaults, so users
+// who never saved anything and event types added later still get values.
+func (ps *PreferenceService) load(ctx context.Context, userID uint) (models.Preferences, error) {
+	preferences := defaultPreferences()
+
+	preference, err := ps.repository.GetPreference().FindByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, errConstant.ErrPreferenceNotFound) {
+			return preferences, nil
+		}
+
+		return preferences, err
+	}
+
+	stored := preference.Preferences
+	if stored.Language != "" {
+		preferences.Language = stored.Language
+	}
+
+	if stored.Timezone != "" {
+		preferences.Timezone = stored.Timezone
+	}
+
+	if stored.PreferredPosition != "" {
+		preferences.PreferredPosition = stored.PreferredPosition
+	}
+
+	for event, channels := range stored.Notifications {
+		if _, ok := preferences.Notifications[event]; ok {
+			preferences.Notifications[event] = channels
+		}
+	}
+
+	return preferences, nil
+}
+
+func defaultPreferences() models.Preferences {
+	timezone := os.Getenv("TIMEZONE")
+	if timezone == "" {
+		timezone = constants.DefaultTimezone
+	}
+
+	notifications := make(map[string]models.NotificationChannels, len(constants.NotificationEvents))
+	for _, event := range constants.NotificationEvents {
+		notifications[event] = models.NotificationChannels{
+			Email: true,
+			Push:  true,
+		}
+	}
+
+	notifications[constants.EventBookingReminder] = models.NotificationChannels{
+		Email: true,
+		SMS:   true,
+		Push:  true,
+	}
+	notifications[constants.EventPromotion] = models.NotificationChannels{}
+
+	return models.Preferences{
+		Language:      constants.DefaultLanguage,
+		Timezone:      timezone,
+		Notifications: notifications,
+	}
+}
+
+func toResponse(preferences models.Preferences) *dto.PreferencesResponse {
+	notifications := make(map[string]dto.NotificationChannelsResponse, len(preferences.Notifications))
+	for event, channels := range preferences.Notifications {
+		notifications[event] = dto.NotificationChannelsResponse{
+			Email: channels.Email,
+			SMS:   channels.SMS,
+			Push:  channels.Push,
+		}
+	}
+
+	return &dto.PreferencesResponse{
+		Language:          preferences.Language,
+		Timezone:          preferences.Timezone,
+		PreferredPosition: preferences.PreferredPosition,
+		Notifications:     notifications,
+	}
+}
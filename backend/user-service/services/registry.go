@@ -1,24 +1,58 @@
 package services
 
 import (
+	"user-service/clients"
+	"user-service/common/storage"
 	"user-service/repositories"
+	"user-service/services/audit"
+	"user-service/services/invitation"
+	"user-service/services/organization"
+	"user-service/services/preference"
 	"user-service/services/user"
 )
 
 type Registry struct {
 	repository repositories.IRepositoryRegistry
+	client     clients.IClientRegistry
+	storage    storage.Storage
 }
 
 type IServiceRegistry interface {
 	GetUser() user.IUserService
+	GetAudit() audit.IAuditService
+	GetInvitation() invitation.IInvitationService
+	GetOrganization() organization.IOrganizationService
+	GetPreference() preference.IPreferenceService
 }
 
-func NewServiceRegistry(repository repositories.IRepositoryRegistry) IServiceRegistry {
+func NewServiceRegistry(
+	repository repositories.IRepositoryRegistry,
+	client clients.IClientRegistry,
+	storage storage.Storage,
+) IServiceRegistry {
 	return &Registry{
 		repository: repository,
+		client:     client,
+		storage:    storage,
 	}
 }
 
 func (r *Registry) GetUser() user.IUserService {
-	return user.NewUserService(r.repository)
+	return user.NewUserService(r.repository, r.client, r.storage)
+}
+
+func (r *Registry) GetAudit() audit.IAuditService {
+	return audit.NewAuditService(r.repository)
+}
+
+func (r *Registry) GetInvitation() invitation.IInvitationService {
+	return invitation.NewInvitationService(r.repository, r.client)
+}
+
+func (r *Registry) GetOrganization() organization.IOrganizationService {
+	return organization.NewOrganizationService(r.repository)
+}
+
+func (r *Registry) GetPreference() preference.IPreferenceService {
+	return preference.NewPreferenceService(r.repository)
 }
@@ -0,0 +1,211 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"user-service/common/hashing"
+	"user-service/common/logger"
+	"user-service/common/telemetry"
+	"user-service/common/util"
+	"user-service/config"
+	"user-service/constants"
+	errConstant "user-service/constants/custom-error"
+	"user-service/domain/dto"
+	"user-service/domain/models"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultEmailChangeExpirationTime = 24
+	defaultEmailChangeRevertTime     = 168
+)
+
+// authorizeEmailChange asks for the current password before the address that
+// receives reset and confirmation links can change. Administrators are
+// trusted to change it for anyone.
+func authorizeEmailChange(ctx context.Context, user *models.User, password string) error {
+	userLogin := ctx.Value(constants.UserLogin).(*dto.UserResponse)
+	if userLogin.Role == constants.AdminCode {
+		return nil
+	}
+
+	if password == "" {
+		return errConstant.ErrPasswordRequired
+	}
+
+	err := hashing.Compare(ctx, []byte(user.Password), []byte(password))
+	if err != nil {
+		return errConstant.ErrPasswordIncorrect
+	}
+
+	return nil
+}
+
+// requestEmailChange saves fields and keeps the new address pending until it
+// is confirmed, then tells the current address how to undo the change.
+func (us *UserService) requestEmailChange(ctx context.Context, user *models.User, email string, version uint, fields map[string]any) (*models.User, error) {
+	confirmToken, err := util.GenerateToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	revertToken, err := util.GenerateToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	expirationTime := config.Get().EmailChangeExpirationTime
+	if expirationTime <= 0 {
+		expirationTime = defaultEmailChangeExpirationTime
+	}
+
+	revertTime := config.Get().EmailChangeRevertTime
+	if revertTime <= 0 {
+		revertTime = defaultEmailChangeRevertTime
+	}
+
+	now := time.Now()
+	change := &models.EmailChange{
+		UUID:             uuid.New(),
+		UserID:           user.ID,
+		PreviousEmail:    user.Email,
+		NewEmail:         email,
+		ConfirmTokenHash: util.HashToken(confirmToken),
+		RevertTokenHash:  util.HashToken(revertToken),
+		ExpiredAt:        now.Add(time.Duration(expirationTime) * time.Hour),
+		RevertExpiredAt:  now.Add(time.Duration(revertTime) * time.Hour),
+	}
+
+	user, err = us.repository.GetEmailChange().Request(ctx, change, user.UUID.String(), version, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	actor := user.UUID
+	if userLogin, ok := ctx.Value(constants.UserLogin).(*dto.UserResponse); ok {
+		actor = userLogin.UUID
+	}
+
+	_, err = us.repository.GetAudit().Create(ctx, &dto.AuditRequest{
+		ActorUUID:   actor,
+		SubjectUUID: &user.UUID,
+		Action:      constants.AuditEmailChangeRequest,
+		Description: fmt.Sprintf("requested email change from %s to %s", change.PreviousEmail, change.NewEmail),
+	})
+	if err != nil {
+		logger.FromContext(ctx).Errorf("failed to record email change request audit: %v", err)
+	}
+
+	err = us.client.GetNotification().SendEmail(ctx, &dto.EmailRequest{
+		To:      change.NewEmail,
+		Subject: fmt.Sprintf("Confirm your new %s email address", config.Get().AppName),
+		Body: fmt.Sprintf(
+			"Confirm this address before %s to start using it: %s/email/confirm?token=%s",
+			change.ExpiredAt.Format(time.RFC1123),
+			config.Get().FrontendURL,
+			confirmToken,
+		),
+	})
+	if err != nil {
+		logger.FromContext(ctx).Errorf("failed to send email change confirmation: %v", err)
+	}
+
+	err = us.client.GetNotification().SendEmail(ctx, &dto.EmailRequest{
+		To:      change.PreviousEmail,
+		Subject: fmt.Sprintf("Your %s email address is being changed", config.Get().AppName),
+		Body: fmt.Sprintf(
+			"A change of your email address to %s was requested. If this was not you, revert it before %s: %s/email/revert?token=%s",
+			change.NewEmail,
+			change.RevertExpiredAt.Format(time.RFC1123),
+			config.Get().FrontendURL,
+			revertToken,
+		),
+	})
+	if err != nil {
+		logger.FromContext(ctx).Errorf("failed to send email change notice: %v", err)
+	}
+
+	return user, nil
+}
+
+func (us *UserService) ConfirmEmail(ctx context.Context, req *dto.EmailChangeTokenRequest) (*dto.UserResponse, error) {
+	ctx, span := telemetry.Start(ctx, "UserService.ConfirmEmail")
+	defer span.End()
+
+	change, err := us.repository.GetEmailChange().FindPendingByConfirmTokenHash(ctx, util.HashToken(req.Token))
+	if err != nil {
+		return nil, err
+	}
+
+	if change.ExpiredAt.Before(time.Now()) {
+		return nil, errConstant.ErrEmailChangeExpired
+	}
+
+	// The address has changed once Confirm returns, so a failed audit is only
+	// logged.
+	user, err := us.repository.GetEmailChange().Confirm(ctx, change)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = us.repository.GetAudit().Create(ctx, &dto.AuditRequest{
+		ActorUUID:   user.UUID,
+		SubjectUUID: &user.UUID,
+		Action:      constants.AuditEmailChangeConfirm,
+		Description: fmt.Sprintf("confirmed email change from %s to %s", change.PreviousEmail, change.NewEmail),
+	})
+	if err != nil {
+		logger.FromContext(ctx).Errorf("failed to record email change confirmation audit: %v", err)
+	}
+
+	return us.toEmailChangeResponse(user), nil
+}
+
+// RevertEmail undoes a change from the link sent to the previous address and
+// signs the account out everywhere.
+func (us *UserService) RevertEmail(ctx context.Context, req *dto.EmailChangeTokenRequest) (*dto.UserResponse, error) {
+	ctx, span := telemetry.Start(ctx, "UserService.RevertEmail")
+	defer span.End()
+
+	change, err := us.repository.GetEmailChange().FindByRevertTokenHash(ctx, util.HashToken(req.Token))
+	if err != nil {
+		return nil, err
+	}
+
+	if change.RevertExpiredAt.Before(time.Now()) {
+		return nil, errConstant.ErrEmailChangeExpired
+	}
+
+	user, err := us.repository.GetEmailChange().Revert(ctx, change)
+	if err != nil {
+		return nil, err
+	}
+	tokenVersions.forget(user.UUID.String())
+
+	_, err = us.repository.GetAudit().Create(ctx, &dto.AuditRequest{
+		ActorUUID:   user.UUID,
+		SubjectUUID: &user.UUID,
+		Action:      constants.AuditEmailChangeRevert,
+		Description: fmt.Sprintf("reverted email change from %s to %s", change.PreviousEmail, change.NewEmail),
+	})
+	if err != nil {
+		logger.FromContext(ctx).Errorf("failed to record email change revert audit: %v", err)
+	}
+
+	return us.toEmailChangeResponse(user), nil
+}
+
+func (us *UserService) toEmailChangeResponse(user *models.User) *dto.UserResponse {
+	return &dto.UserResponse{
+		UUID:        user.UUID,
+		Name:        user.Name,
+		Username:    user.Username,
+		Email:       user.Email,
+		PhoneNumber: user.PhoneNumber,
+		Role:        user.Role.Code,
+		Avatar:      us.avatar(user),
+		Version:     user.Version,
+	}
+}
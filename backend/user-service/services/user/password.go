@@ -0,0 +1,125 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"user-service/common/hashing"
+	"user-service/common/logger"
+	"user-service/common/telemetry"
+	"user-service/common/util"
+	"user-service/config"
+	"user-service/constants"
+	errConstant "user-service/constants/custom-error"
+	"user-service/domain/dto"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ChangePassword replaces the password of the logged in user. Every other
+// session is revoked through the token version, so a fresh token is returned
+// for the caller.
+func (us *UserService) ChangePassword(ctx context.Context, req *dto.ChangePasswordRequest) (*dto.LoginResponse, error) {
+	ctx, span := telemetry.Start(ctx, "UserService.ChangePassword")
+	defer span.End()
+
+	userLogin := ctx.Value(constants.UserLogin).(*dto.UserResponse)
+
+	user, err := us.repository.GetUser().FindByUUID(ctx, userLogin.UUID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	err = hashing.Compare(ctx, []byte(user.Password), []byte(req.CurrentPassword))
+	if err != nil {
+		return nil, errConstant.ErrPasswordIncorrect
+	}
+
+	err = util.CheckPasswordPolicy(req.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	histories, err := us.repository.GetPasswordHistory().FindRecent(ctx, user.ID, constants.PasswordHistorySize)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := []string{user.Password}
+	for _, history := range histories {
+		previous = append(previous, history.Password)
+	}
+
+	for _, hash := range previous {
+		if hashing.Compare(ctx, []byte(hash), []byte(req.NewPassword)) == nil {
+			return nil, errConstant.ErrPasswordReused
+		}
+	}
+
+	hashedPass, err := hashing.Generate(ctx, []byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err = us.repository.GetUser().UpdatePassword(ctx, user.ID, string(hashedPass))
+	if err != nil {
+		return nil, err
+	}
+	tokenVersions.forget(user.UUID.String())
+
+	// The password is already changed and every session revoked, so a failed
+	// audit must not keep the caller from getting a working token.
+	_, err = us.repository.GetAudit().Create(ctx, &dto.AuditRequest{
+		ActorUUID:   user.UUID,
+		SubjectUUID: &user.UUID,
+		Action:      constants.AuditPasswordChange,
+		Description: "changed password and revoked other sessions",
+	})
+	if err != nil {
+		logger.FromContext(ctx).Errorf("failed to record password change audit: %v", err)
+	}
+
+	err = us.client.GetNotification().SendEmail(ctx, &dto.EmailRequest{
+		To:      user.Email,
+		Subject: fmt.Sprintf("Your %s password was changed", config.Get().AppName),
+		Body: fmt.Sprintf(
+			"Your password was changed on %s and every other session was signed out. If this was not you, reset your password immediately.",
+			time.Now().Format(time.RFC1123),
+		),
+	})
+	if err != nil {
+		logger.FromContext(ctx).Errorf("failed to send password change notice: %v", err)
+	}
+
+	data := &dto.UserResponse{
+		UUID:        user.UUID,
+		Name:        user.Name,
+		Username:    user.Username,
+		Email:       user.Email,
+		PhoneNumber: user.PhoneNumber,
+		Role:        user.Role.Code,
+	}
+
+	claims := &Claims{
+		User:         data,
+		TokenVersion: user.TokenVersion,
+	}
+	if organization, ok := ctx.Value(constants.OrganizationClaim).(*uuid.UUID); ok {
+		claims.Organization = organization
+	}
+
+	expiryTime := time.Now().Add(time.Duration(config.Get().JwtExpirationTime) * time.Minute)
+	tokenString, err := us.generateToken(claims, expiryTime)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.LoginResponse{
+		User:  *data,
+		Token: tokenString,
+	}
+	response.User.Avatar = us.avatar(user)
+
+	return response, nil
+}
@@ -0,0 +1,61 @@
+package user
+
+import (
+	"sync"
+	"time"
+)
+
+// tokenVersionTTL bounds how long a token revoked on another instance keeps
+// working. Revocations made by this instance take effect immediately.
+const tokenVersionTTL = 10 * time.Second
+
+type cachedVersion struct {
+	version   uint
+	expiresAt time.Time
+}
+
+// versionCache remembers the token version of recently authenticated users
+// so most requests skip the database.
+type versionCache struct {
+	mu        sync.Mutex
+	entries   map[string]cachedVersion
+	lastSweep time.Time
+}
+
+var tokenVersions = &versionCache{entries: make(map[string]cachedVersion)}
+
+func (vc *versionCache) get(uuid string) (uint, bool) {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	entry, ok := vc.entries[uuid]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return 0, false
+	}
+
+	return entry.version, true
+}
+
+func (vc *versionCache) set(uuid string, version uint) {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(vc.lastSweep) > tokenVersionTTL {
+		for key, entry := range vc.entries {
+			if now.After(entry.expiresAt) {
+				delete(vc.entries, key)
+			}
+		}
+		vc.lastSweep = now
+	}
+
+	vc.entries[uuid] = cachedVersion{version: version, expiresAt: now.Add(tokenVersionTTL)}
+}
+
+func (vc *versionCache) forget(uuid string) {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	delete(vc.entries, uuid)
+}
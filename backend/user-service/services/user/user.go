@@ -1,8 +1,25 @@
 package user
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"mime/multipart"
+	"strconv"
+	"strings"
 	"time"
+	"user-service/clients"
+	"user-service/common/hashing"
+	"user-service/common/imaging"
+	"user-service/common/jwtkey"
+	"user-service/common/logger"
+	"user-service/common/metrics"
+	"user-service/common/storage"
+	"user-service/common/telemetry"
+	"user-service/common/util"
 	"user-service/config"
 	"user-service/constants"
 	errConstant "user-service/constants/custom-error"
@@ -11,36 +28,84 @@ import (
 	"user-service/repositories"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	defaultImpersonationExpirationTime = 15
+	defaultAvatarMaxSize               = 5 << 20
+	maxUsernameLength                  = 20
+	maxUsernameSuggestions             = 3
+)
+
+var avatarVariants = []struct {
+	name string
+	size int
+}{
+	{name: "small", size: 64},
+	{name: "medium", size: 256},
+	{name: "large", size: 512},
+}
+
 type UserService struct {
 	repository repositories.IRepositoryRegistry
+	client     clients.IClientRegistry
+	storage    storage.Storage
 }
 
 type IUserService interface {
 	Login(context.Context, *dto.LoginRequest) (*dto.LoginResponse, error)
 	Register(context.Context, *dto.RegisterRequest) (*dto.RegisterResponse, error)
-	Update(context.Context, *dto.UpdateRequest, string) (*dto.UserResponse, error)
+	CreateAdmin(context.Context, *dto.RegisterRequest) (*dto.UserResponse, error)
+	Update(context.Context, *dto.UpdateRequest, string, uint) (*dto.UserResponse, error)
+	Patch(context.Context, *dto.PatchUserRequest, string, uint) (*dto.UserResponse, error)
 	GetUserLogin(context.Context) (*dto.UserResponse, error)
 	GetUserByUUID(context.Context, string) (*dto.UserResponse, error)
-	IsUsernameExist(context.Context, string) bool
-	IsEmailExist(context.Context, string) bool
+	Impersonate(context.Context, *dto.ImpersonateRequest, string) (*dto.ImpersonateResponse, error)
+	UpdateRole(context.Context, *dto.UpdateRoleRequest, string) (*dto.UserResponse, error)
+	BulkUpdateRole(context.Context, *dto.BulkUpdateRoleRequest) ([]dto.UserResponse, error)
+	ValidateTokenVersion(context.Context, string, uint) error
+	UpdateAvatar(context.Context, *multipart.FileHeader) (*dto.UserResponse, error)
+	CheckAvailability(context.Context, *dto.AvailabilityRequest) (*dto.AvailabilityResponse, error)
+	ConfirmEmail(context.Context, *dto.EmailChangeTokenRequest) (*dto.UserResponse, error)
+	ChangePassword(context.Context, *dto.ChangePasswordRequest) (*dto.LoginResponse, error)
+	RevertEmail(context.Context, *dto.EmailChangeTokenRequest) (*dto.UserResponse, error)
+	IsUsernameExist(context.Context, string) (bool, error)
+	IsEmailExist(context.Context, string) (bool, error)
 }
 
+// Claims is the JWT payload. Actor is only present on impersonation tokens
+// and identifies the administrator acting on behalf of User.
 type Claims struct {
-	User *dto.UserResponse
+	User         *dto.UserResponse
+	Actor        *dto.Actor `json:"act,omitempty"`
+	Organization *uuid.UUID `json:"org,omitempty"`
+	TokenVersion uint       `json:"tokenVersion"`
 	jwt.RegisteredClaims
 }
 
-func NewUserService(repository repositories.IRepositoryRegistry) IUserService {
+func (c *Claims) IsImpersonated() bool {
+	return c.Actor != nil
+}
+
+func NewUserService(
+	repository repositories.IRepositoryRegistry,
+	client clients.IClientRegistry,
+	storage storage.Storage,
+) IUserService {
 	return &UserService{
 		repository: repository,
+		client:     client,
+		storage:    storage,
 	}
 }
 
 func (us *UserService) GetUserByUUID(ctx context.Context, uuid string) (*dto.UserResponse, error) {
+	ctx, span := telemetry.Start(ctx, "UserService.GetUserByUUID")
+	defer span.End()
+
 	user, err := us.repository.GetUser().FindByUUID(ctx, uuid)
 	if err != nil {
 		return nil, err
@@ -53,41 +118,44 @@ func (us *UserService) GetUserByUUID(ctx context.Context, uuid string) (*dto.Use
 		Email:       user.Email,
 		PhoneNumber: user.PhoneNumber,
 		Role:        user.Role.Code,
+		Avatar:      us.avatar(user),
+		Version:     user.Version,
 	}
 
 	return &data, nil
 }
 
 func (us *UserService) GetUserLogin(ctx context.Context) (*dto.UserResponse, error) {
-	var (
-		userLogin = ctx.Value(constants.UserLogin).(*dto.UserResponse)
-		data      dto.UserResponse
-	)
+	ctx, span := telemetry.Start(ctx, "UserService.GetUserLogin")
+	defer span.End()
 
-	data = dto.UserResponse{
-		UUID:        userLogin.UUID,
-		Name:        userLogin.Name,
-		Email:       userLogin.Email,
-		Username:    userLogin.Username,
-		PhoneNumber: userLogin.PhoneNumber,
-		Role:        userLogin.Role,
-	}
+	userLogin := ctx.Value(constants.UserLogin).(*dto.UserResponse)
 
-	return &data, nil
+	return us.GetUserByUUID(ctx, userLogin.UUID.String())
 }
 
 func (us *UserService) Login(ctx context.Context, req *dto.LoginRequest) (*dto.LoginResponse, error) {
-	user, err := us.repository.GetUser().FindByUsername(ctx, req.Username)
+	ctx, span := telemetry.Start(ctx, "UserService.Login")
+	defer span.End()
+
+	user, err := us.repository.GetUser().FindByUsername(ctx, util.NormalizeUsername(req.Username))
 	if err != nil {
+		reason := "error"
+		if errors.Is(err, errConstant.ErrUserNotFound) {
+			reason = "user_not_found"
+		}
+		metrics.Logins.WithLabelValues(metrics.LoginFailure, reason).Inc()
+
 		return nil, err
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
+	err = hashing.Compare(ctx, []byte(user.Password), []byte(req.Password))
 	if err != nil {
+		metrics.Logins.WithLabelValues(metrics.LoginFailure, "password_incorrect").Inc()
 		return nil, err
 	}
 
-	expiryTime := time.Now().Add(time.Duration(config.Config.JwtExpirationTime) * time.Minute).Unix()
+	expiryTime := time.Now().Add(time.Duration(config.Get().JwtExpirationTime) * time.Minute)
 	data := &dto.UserResponse{
 		UUID:        user.UUID,
 		Name:        user.Name,
@@ -96,16 +164,34 @@ func (us *UserService) Login(ctx context.Context, req *dto.LoginRequest) (*dto.L
 		PhoneNumber: user.PhoneNumber,
 		Role:        user.Role.Code,
 	}
+
 	claims := &Claims{
-		User: data,
-		RegisteredClaims: jwt.RegisteredClaims{
-			Issuer:    "user-service",
-			ExpiresAt: jwt.NewNumericDate(time.Unix(expiryTime, 0)),
-		},
+		User:         data,
+		TokenVersion: user.TokenVersion,
 	}
+	if req.OrganizationUUID != nil {
+		organization, err := us.repository.GetOrganization().FindByUUID(ctx, *req.OrganizationUUID)
+		if err != nil {
+			metrics.Logins.WithLabelValues(metrics.LoginFailure, "organization_not_found").Inc()
+			return nil, err
+		}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(config.Config.JwtSecretKey))
+		if user.Role.Code != constants.AdminCode {
+			_, err = us.repository.GetMembership().FindByUserAndOrganization(ctx, user.UUID.String(), organization.ID)
+			if err != nil {
+				if errors.Is(err, errConstant.ErrMembershipNotFound) {
+					metrics.Logins.WithLabelValues(metrics.LoginFailure, "not_a_member").Inc()
+					return nil, errConstant.ErrNotMember
+				}
+
+				return nil, err
+			}
+		}
+
+		claims.Organization = &organization.UUID
+	}
+
+	tokenString, err := us.generateToken(claims, expiryTime)
 	if err != nil {
 		return nil, err
 	}
@@ -114,16 +200,103 @@ func (us *UserService) Login(ctx context.Context, req *dto.LoginRequest) (*dto.L
 		User:  *data,
 		Token: tokenString,
 	}
+	response.User.Avatar = us.avatar(user)
+	metrics.Logins.WithLabelValues(metrics.LoginSuccess, "").Inc()
+
+	return response, nil
+}
+
+func (us *UserService) Impersonate(ctx context.Context, req *dto.ImpersonateRequest, uuid string) (*dto.ImpersonateResponse, error) {
+	ctx, span := telemetry.Start(ctx, "UserService.Impersonate")
+	defer span.End()
+
+	admin := ctx.Value(constants.UserLogin).(*dto.UserResponse)
+	if admin.UUID.String() == uuid {
+		return nil, errConstant.ErrCannotImpersonateSelf
+	}
+
+	user, err := us.repository.GetUser().FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Role.Code == constants.AdminCode {
+		return nil, errConstant.ErrCannotImpersonateAdmin
+	}
+
+	expirationTime := config.Get().ImpersonationExpirationTime
+	if expirationTime <= 0 {
+		expirationTime = defaultImpersonationExpirationTime
+	}
+
+	expiryTime := time.Now().Add(time.Duration(expirationTime) * time.Minute)
+	data := &dto.UserResponse{
+		UUID:        user.UUID,
+		Name:        user.Name,
+		Username:    user.Username,
+		Email:       user.Email,
+		PhoneNumber: user.PhoneNumber,
+		Role:        user.Role.Code,
+	}
+	actor := &dto.Actor{
+		UUID:     admin.UUID,
+		Username: admin.Username,
+		Role:     admin.Role,
+	}
+
+	tokenString, err := us.generateToken(&Claims{User: data, Actor: actor, TokenVersion: user.TokenVersion}, expiryTime)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = us.repository.GetAudit().Create(ctx, &dto.AuditRequest{
+		ActorUUID:   admin.UUID,
+		SubjectUUID: &user.UUID,
+		Action:      constants.AuditImpersonationStart,
+		Description: req.Reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"actor":   admin.UUID,
+		"subject": user.UUID,
+		"expires": expiryTime,
+	}).Warn("impersonation token issued")
+
+	response := &dto.ImpersonateResponse{
+		User:      *data,
+		Actor:     *actor,
+		ExpiresAt: expiryTime,
+		Token:     tokenString,
+	}
 
 	return response, nil
 }
 
 func (us *UserService) Register(ctx context.Context, req *dto.RegisterRequest) (*dto.RegisterResponse, error) {
-	if us.IsUsernameExist(ctx, req.Username) {
+	ctx, span := telemetry.Start(ctx, "UserService.Register")
+	defer span.End()
+
+	req.Username = util.NormalizeUsername(req.Username)
+	req.Email = util.NormalizeEmail(req.Email)
+
+	usernameExist, err := us.IsUsernameExist(ctx, req.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	if usernameExist {
 		return nil, errConstant.ErrUsernameExist
 	}
 
-	if us.IsEmailExist(ctx, req.Email) {
+	emailExist, err := us.IsEmailExist(ctx, req.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if emailExist {
 		return nil, errConstant.ErrEmailExist
 	}
 
@@ -131,7 +304,12 @@ func (us *UserService) Register(ctx context.Context, req *dto.RegisterRequest) (
 		return nil, errConstant.ErrPasswordDoesNotMatch
 	}
 
-	hashedPass, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	err = util.CheckPasswordPolicy(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	hashedPass, err := hashing.Generate(ctx, []byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, err
 	}
@@ -159,83 +337,617 @@ func (us *UserService) Register(ctx context.Context, req *dto.RegisterRequest) (
 		},
 	}
 
+	metrics.Registrations.WithLabelValues(metrics.RegistrationSelf).Inc()
+
 	return response, nil
 }
 
-func (us *UserService) Update(ctx context.Context, req *dto.UpdateRequest, uuid string) (*dto.UserResponse, error) {
-	var (
-		password   string
-		hashedPass []byte
-		user       *models.User
-		err        error
-		data       dto.UserResponse
-	)
+// CreateAdmin bootstraps an administrator from the command line.
+func (us *UserService) CreateAdmin(ctx context.Context, req *dto.RegisterRequest) (*dto.UserResponse, error) {
+	ctx, span := telemetry.Start(ctx, "UserService.CreateAdmin")
+	defer span.End()
+
+	req.Username = util.NormalizeUsername(req.Username)
+	req.Email = util.NormalizeEmail(req.Email)
 
-	user, err = us.repository.GetUser().FindByUUID(ctx, uuid)
+	usernameExist, err := us.IsUsernameExist(ctx, req.Username)
 	if err != nil {
 		return nil, err
 	}
 
-	isExist := us.IsUsernameExist(ctx, req.Username)
-	if isExist && user.Username != req.Username {
+	if usernameExist {
 		return nil, errConstant.ErrUsernameExist
 	}
 
-	isExist = us.IsEmailExist(ctx, req.Email)
-	if isExist && user.Email != req.Email {
+	emailExist, err := us.IsEmailExist(ctx, req.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if emailExist {
 		return nil, errConstant.ErrEmailExist
 	}
 
-	if req.Password != nil {
-		if *req.Password != *req.ConfirmPassword {
-			logrus.Infof("Password and ConfirmPassword did not match: %s - %s", *req.Password, *req.ConfirmPassword)
-			return nil, errConstant.ErrPasswordDoesNotMatch
-		}
+	err = util.CheckPasswordPolicy(req.Password)
+	if err != nil {
+		return nil, err
+	}
 
-		hashedPass, err = bcrypt.GenerateFromPassword([]byte(*req.Password), bcrypt.DefaultCost)
-		if err != nil {
-			return nil, err
-		}
+	hashedPass, err := hashing.Generate(ctx, []byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
 	}
 
-	password = string(hashedPass)
-	user, err = us.repository.GetUser().Update(ctx, &dto.UpdateRequest{
+	user, err := us.repository.GetUser().Register(ctx, &dto.RegisterRequest{
 		Name:        req.Name,
 		Username:    req.Username,
 		Email:       req.Email,
-		Password:    &password,
+		Password:    string(hashedPass),
 		PhoneNumber: req.PhoneNumber,
-	}, uuid)
+		RoleID:      constants.Admin,
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	data = dto.UserResponse{
+	return &dto.UserResponse{
 		UUID:        user.UUID,
 		Name:        user.Name,
+		Username:    user.Username,
 		Email:       user.Email,
+		PhoneNumber: user.PhoneNumber,
+		Role:        constants.AdminCode,
+	}, nil
+}
+
+func (us *UserService) Update(ctx context.Context, req *dto.UpdateRequest, uuid string, version uint) (*dto.UserResponse, error) {
+	ctx, span := telemetry.Start(ctx, "UserService.Update")
+	defer span.End()
+
+	err := authorizeAccountChange(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		user *models.User
+		data dto.UserResponse
+	)
+
+	user, err = us.repository.GetUser().FindByUUIDFromPrimary(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Version != version {
+		return nil, errConstant.ErrVersionMismatch
+	}
+
+	req.Username = util.NormalizeUsername(req.Username)
+	req.Email = util.NormalizeEmail(req.Email)
+
+	if util.NormalizeUsername(user.Username) != req.Username {
+		isExist, err := us.IsUsernameExist(ctx, req.Username)
+		if err != nil {
+			return nil, err
+		}
+
+		if isExist {
+			return nil, errConstant.ErrUsernameExist
+		}
+	}
+
+	var pendingEmail string
+	if util.NormalizeEmail(user.Email) != req.Email {
+		err = authorizeEmailChange(ctx, user, req.CurrentPassword)
+		if err != nil {
+			return nil, err
+		}
+
+		isExist, err := us.IsEmailExist(ctx, req.Email)
+		if err != nil {
+			return nil, err
+		}
+
+		if isExist {
+			return nil, errConstant.ErrEmailExist
+		}
+
+		pendingEmail = req.Email
+	}
+
+	if pendingEmail != "" {
+		user, err = us.requestEmailChange(ctx, user, pendingEmail, version, map[string]any{
+			"name":         req.Name,
+			"username":     req.Username,
+			"phone_number": req.PhoneNumber,
+		})
+	} else {
+		user, err = us.repository.GetUser().Update(ctx, &dto.UpdateRequest{
+			Name:        req.Name,
+			Username:    req.Username,
+			Email:       user.Email,
+			PhoneNumber: req.PhoneNumber,
+		}, uuid, version)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data = dto.UserResponse{
+		UUID:         user.UUID,
+		Name:         user.Name,
+		Email:        user.Email,
+		PendingEmail: pendingEmail,
+		Username:     user.Username,
+		PhoneNumber:  user.PhoneNumber,
+		Role:         user.Role.Code,
+		Avatar:       us.avatar(user),
+		Version:      user.Version,
+	}
+
+	return &data, nil
+}
+
+func (us *UserService) Patch(ctx context.Context, req *dto.PatchUserRequest, uuid string, version uint) (*dto.UserResponse, error) {
+	ctx, span := telemetry.Start(ctx, "UserService.Patch")
+	defer span.End()
+
+	err := authorizeAccountChange(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := us.repository.GetUser().FindByUUIDFromPrimary(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Version != version {
+		return nil, errConstant.ErrVersionMismatch
+	}
+
+	fields := make(map[string]any)
+	if req.Name != nil {
+		fields["name"] = *req.Name
+	}
+
+	if req.Username != nil {
+		username := util.NormalizeUsername(*req.Username)
+		if username != util.NormalizeUsername(user.Username) {
+			isExist, err := us.IsUsernameExist(ctx, username)
+			if err != nil {
+				return nil, err
+			}
+
+			if isExist {
+				return nil, errConstant.ErrUsernameExist
+			}
+		}
+
+		fields["username"] = username
+	}
+
+	var pendingEmail string
+	if req.Email != nil {
+		email := util.NormalizeEmail(*req.Email)
+		if email != util.NormalizeEmail(user.Email) {
+			var password string
+			if req.CurrentPassword != nil {
+				password = *req.CurrentPassword
+			}
+
+			err = authorizeEmailChange(ctx, user, password)
+			if err != nil {
+				return nil, err
+			}
+
+			isExist, err := us.IsEmailExist(ctx, email)
+			if err != nil {
+				return nil, err
+			}
+
+			if isExist {
+				return nil, errConstant.ErrEmailExist
+			}
+
+			pendingEmail = email
+		}
+	}
+
+	if req.PhoneNumber != nil {
+		fields["phone_number"] = *req.PhoneNumber
+	}
+
+	switch {
+	case pendingEmail != "":
+		user, err = us.requestEmailChange(ctx, user, pendingEmail, version, fields)
+	case len(fields) > 0:
+		user, err = us.repository.GetUser().UpdateFields(ctx, uuid, version, fields)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data := dto.UserResponse{
+		UUID:         user.UUID,
+		Name:         user.Name,
+		Username:     user.Username,
+		Email:        user.Email,
+		PendingEmail: pendingEmail,
+		PhoneNumber:  user.PhoneNumber,
+		Role:         user.Role.Code,
+		Avatar:       us.avatar(user),
+		Version:      user.Version,
+	}
+
+	return &data, nil
+}
+
+func (us *UserService) UpdateRole(ctx context.Context, req *dto.UpdateRoleRequest, uuid string) (*dto.UserResponse, error) {
+	ctx, span := telemetry.Start(ctx, "UserService.UpdateRole")
+	defer span.End()
+
+	users, err := us.BulkUpdateRole(ctx, &dto.BulkUpdateRoleRequest{
+		Users: []dto.RoleAssignment{
+			{
+				UUID:   uuid,
+				RoleID: req.RoleID,
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &users[0], nil
+}
+
+func (us *UserService) BulkUpdateRole(ctx context.Context, req *dto.BulkUpdateRoleRequest) ([]dto.UserResponse, error) {
+	ctx, span := telemetry.Start(ctx, "UserService.BulkUpdateRole")
+	defer span.End()
+
+	admin := ctx.Value(constants.UserLogin).(*dto.UserResponse)
+
+	seen := make(map[string]bool, len(req.Users))
+	for _, assignment := range req.Users {
+		if seen[assignment.UUID] {
+			return nil, errConstant.ErrDuplicateRoleUsers
+		}
+		seen[assignment.UUID] = true
+
+		_, err := us.repository.GetRole().FindByID(ctx, assignment.RoleID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	updates, err := us.repository.GetUser().UpdateRoles(ctx, req.Users)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]dto.UserResponse, 0, len(updates))
+	for _, update := range updates {
+		user := update.User
+		if update.Changed {
+			tokenVersions.forget(user.UUID.String())
+			_, err = us.repository.GetAudit().Create(ctx, &dto.AuditRequest{
+				ActorUUID:   admin.UUID,
+				SubjectUUID: &user.UUID,
+				Action:      constants.AuditRoleUpdate,
+				Description: fmt.Sprintf("role set to %s", user.Role.Code),
+			})
+			if err != nil {
+				logger.FromContext(ctx).Errorf("failed to record role audit: %v", err)
+			}
+		}
+
+		data = append(data, dto.UserResponse{
+			UUID:        user.UUID,
+			Name:        user.Name,
+			Username:    user.Username,
+			Email:       user.Email,
+			PhoneNumber: user.PhoneNumber,
+			Role:        user.Role.Code,
+			Avatar:      us.avatar(&user),
+		})
+	}
+
+	return data, nil
+}
+
+// authorizeAccountChange only lets users change their own account, unless
+// they are an administrator.
+func authorizeAccountChange(ctx context.Context, uuid string) error {
+	userLogin := ctx.Value(constants.UserLogin).(*dto.UserResponse)
+	if userLogin.Role == constants.AdminCode || strings.EqualFold(userLogin.UUID.String(), uuid) {
+		return nil
+	}
+
+	return errConstant.ErrForbidden
+}
+
+// ValidateTokenVersion rejects tokens issued before the last revocation of the
+// user. See tokenVersionTTL for how quickly revocations propagate.
+func (us *UserService) ValidateTokenVersion(ctx context.Context, uuid string, version uint) error {
+	ctx, span := telemetry.Start(ctx, "UserService.ValidateTokenVersion")
+	defer span.End()
+
+	// Only a match is served from the cache. A newer token than the cached
+	// version, e.g. one issued by another instance, is checked again.
+	if cached, ok := tokenVersions.get(uuid); ok && cached == version {
+		return nil
+	}
+
+	current, err := us.repository.GetUser().FindTokenVersion(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	tokenVersions.set(uuid, current)
+
+	if current != version {
+		return errConstant.ErrTokenRevoked
+	}
+
+	return nil
+}
+
+func (us *UserService) UpdateAvatar(ctx context.Context, file *multipart.FileHeader) (*dto.UserResponse, error) {
+	ctx, span := telemetry.Start(ctx, "UserService.UpdateAvatar")
+	defer span.End()
+
+	userLogin := ctx.Value(constants.UserLogin).(*dto.UserResponse)
+
+	maxSize := config.Get().AvatarMaxSize
+	if maxSize <= 0 {
+		maxSize = defaultAvatarMaxSize
+	}
+
+	if file.Size > maxSize {
+		return nil, errConstant.ErrAvatarTooLarge
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	content, err := io.ReadAll(io.LimitReader(src, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(content)) > maxSize {
+		return nil, errConstant.ErrAvatarTooLarge
+	}
+
+	img, err := imaging.Decode(content)
+	if err != nil {
+		if errors.Is(err, imaging.ErrTooLarge) {
+			return nil, errConstant.ErrAvatarInvalidImage
+		}
+
+		return nil, errConstant.ErrAvatarInvalidType
+	}
+
+	suffix, err := util.GenerateToken(8)
+	if err != nil {
+		return nil, err
+	}
+
+	avatarKey := fmt.Sprintf("avatars/%s/%s", userLogin.UUID, suffix)
+	for i, variant := range avatarVariants {
+		encoded, err := imaging.EncodeJPEG(imaging.Thumbnail(img, variant.size))
+		if err == nil {
+			key := avatarVariantKey(avatarKey, variant.name)
+			err = us.storage.Put(ctx, key, bytes.NewReader(encoded), int64(len(encoded)), "image/jpeg")
+		}
+
+		if err != nil {
+			logger.FromContext(ctx).Errorf("failed to store avatar variant %s: %v", variant.name, err)
+			us.deleteAvatar(ctx, avatarKey, i)
+			return nil, err
+		}
+	}
+
+	previous, err := us.repository.GetUser().FindByUUID(ctx, userLogin.UUID.String())
+	if err != nil {
+		us.deleteAvatar(ctx, avatarKey, len(avatarVariants))
+		return nil, err
+	}
+
+	user, err := us.repository.GetUser().UpdateAvatar(ctx, userLogin.UUID.String(), avatarKey)
+	if err != nil {
+		us.deleteAvatar(ctx, avatarKey, len(avatarVariants))
+		return nil, err
+	}
+
+	if previous.AvatarKey != "" {
+		us.deleteAvatar(ctx, previous.AvatarKey, len(avatarVariants))
+	}
+
+	data := dto.UserResponse{
+		UUID:        user.UUID,
+		Name:        user.Name,
 		Username:    user.Username,
+		Email:       user.Email,
 		PhoneNumber: user.PhoneNumber,
 		Role:        user.Role.Code,
+		Avatar:      us.avatar(user),
 	}
 
 	return &data, nil
 }
 
-func (us *UserService) IsUsernameExist(ctx context.Context, username string) bool {
-	user, _ := us.repository.GetUser().FindByUsername(ctx, username)
-	if user != nil {
-		return true
+// deleteAvatar removes the first n variants stored under avatarKey. Failures
+// only leave orphaned files behind, so they are logged and not returned.
+func (us *UserService) deleteAvatar(ctx context.Context, avatarKey string, n int) {
+	for _, variant := range avatarVariants[:n] {
+		err := us.storage.Delete(ctx, avatarVariantKey(avatarKey, variant.name))
+		if err != nil {
+			logger.FromContext(ctx).Errorf("failed to delete avatar variant %s: %v", variant.name, err)
+		}
+	}
+}
+
+func (us *UserService) avatar(user *models.User) *dto.AvatarResponse {
+	if user.AvatarKey == "" {
+		return nil
 	}
 
-	return false
+	return &dto.AvatarResponse{
+		Small:  us.storage.URL(avatarVariantKey(user.AvatarKey, "small")),
+		Medium: us.storage.URL(avatarVariantKey(user.AvatarKey, "medium")),
+		Large:  us.storage.URL(avatarVariantKey(user.AvatarKey, "large")),
+	}
+}
+
+func avatarVariantKey(avatarKey, variant string) string {
+	return fmt.Sprintf("%s/%s.jpg", avatarKey, variant)
 }
 
-func (us *UserService) IsEmailExist(ctx context.Context, email string) bool {
-	user, _ := us.repository.GetUser().FindByEmail(ctx, email)
-	if user != nil {
-		return true
+func (us *UserService) CheckAvailability(ctx context.Context, req *dto.AvailabilityRequest) (*dto.AvailabilityResponse, error) {
+	ctx, span := telemetry.Start(ctx, "UserService.CheckAvailability")
+	defer span.End()
+
+	response := &dto.AvailabilityResponse{}
+
+	if req.Username != "" {
+		username := util.NormalizeUsername(req.Username)
+		isExist, err := us.IsUsernameExist(ctx, username)
+		if err != nil {
+			return nil, err
+		}
+
+		response.Username = &dto.FieldAvailability{
+			Value:     username,
+			Available: !isExist,
+		}
+
+		if !response.Username.Available {
+			suggestions, err := us.suggestUsernames(ctx, username)
+			if err != nil {
+				return nil, err
+			}
+
+			response.Username.Suggestions = suggestions
+		}
+	}
+
+	if req.Email != "" {
+		email := util.NormalizeEmail(req.Email)
+		isExist, err := us.IsEmailExist(ctx, email)
+		if err != nil {
+			return nil, err
+		}
+
+		response.Email = &dto.FieldAvailability{
+			Value:     email,
+			Available: !isExist,
+		}
+	}
+
+	return response, nil
+}
+
+// suggestUsernames derives candidates from a taken username and returns the
+// first few that are still free, checking all of them in a single query.
+func (us *UserService) suggestUsernames(ctx context.Context, username string) ([]string, error) {
+	base := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' || r == '.' {
+			return r
+		}
+
+		return -1
+	}, username)
+	if base == "" {
+		base = "player"
+	}
+
+	if len(base) > maxUsernameLength-4 {
+		base = base[:maxUsernameLength-4]
+	}
+
+	candidates := []string{strconv.Itoa(time.Now().Year())}
+	for i := 0; i < 3; i++ {
+		candidates = append(candidates, strconv.Itoa(rand.IntN(90)+10))
+		candidates = append(candidates, "_"+strconv.Itoa(rand.IntN(900)+100))
+	}
+
+	seen := make(map[string]bool, len(candidates))
+	usernames := make([]string, 0, len(candidates))
+	for _, suffix := range candidates {
+		candidate := base + suffix
+		if len(candidate) > maxUsernameLength || seen[candidate] {
+			continue
+		}
+
+		seen[candidate] = true
+		usernames = append(usernames, candidate)
+	}
+
+	existing, err := us.repository.GetUser().FindExistingUsernames(ctx, usernames)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, username := range existing {
+		delete(seen, username)
+	}
+
+	suggestions := make([]string, 0, maxUsernameSuggestions)
+	for _, candidate := range usernames {
+		if seen[candidate] && len(suggestions) < maxUsernameSuggestions {
+			suggestions = append(suggestions, candidate)
+		}
+	}
+
+	return suggestions, nil
+}
+
+func (us *UserService) generateToken(claims *Claims, expiryTime time.Time) (string, error) {
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		Issuer:    "user-service",
+		Subject:   claims.User.UUID.String(),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		ExpiresAt: jwt.NewNumericDate(expiryTime),
+	}
+
+	return jwtkey.Sign(claims)
+}
+
+func (us *UserService) IsUsernameExist(ctx context.Context, username string) (bool, error) {
+	ctx, span := telemetry.Start(ctx, "UserService.IsUsernameExist")
+	defer span.End()
+
+	_, err := us.repository.GetUser().FindByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, errConstant.ErrUserNotFound) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// IsEmailExist also treats addresses held by a pending or revertible email
+// change as taken.
+func (us *UserService) IsEmailExist(ctx context.Context, email string) (bool, error) {
+	ctx, span := telemetry.Start(ctx, "UserService.IsEmailExist")
+	defer span.End()
+
+	_, err := us.repository.GetUser().FindByEmail(ctx, email)
+	if err == nil {
+		return true, nil
+	}
+
+	if !errors.Is(err, errConstant.ErrUserNotFound) {
+		return false, err
 	}
 
-	return false
+	return us.repository.GetEmailChange().IsEmailReserved(ctx, email)
 }